@@ -0,0 +1,271 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/onboard"
+)
+
+// EventIssueComment is the name of the GitHub event of issue and pull request comments
+const EventIssueComment = "issue_comment"
+
+// Command represents a slash command in a comment
+type Command string
+
+const (
+	// CommandAssign assigns the issue to the commenter
+	CommandAssign Command = "/assign"
+	// CommandTake is an alias of CommandAssign
+	CommandTake Command = "/take"
+	// CommandUnassign unassigns the commenter from the issue
+	CommandUnassign Command = "/unassign"
+)
+
+// Manager handles GitHub events for the repository
+type Manager struct {
+	client *api.RESTClient
+}
+
+// NewManager creates a new bot manager
+func NewManager(client *api.RESTClient) *Manager {
+	return &Manager{
+		client: client,
+	}
+}
+
+// User represents a GitHub user
+type User struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// IsBot reports whether the user is a bot account
+func (u User) IsBot() bool {
+	return u.Type == "Bot" || strings.HasSuffix(u.Login, "[bot]")
+}
+
+// IssueCommentEvent represents the payload of an issue_comment event
+type IssueCommentEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number int    `json:"number"`
+		State  string `json:"state"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Assignees   []User    `json:"assignees"`
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+	Comment struct {
+		Body string `json:"body"`
+		User User   `json:"user"`
+	} `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// HandleEvent handles the payload of a GitHub event, ignoring the events that
+// are not supported
+func (m *Manager) HandleEvent(ctx context.Context, name string, payload []byte, opts onboard.Options) error {
+	switch name {
+	case EventIssueComment:
+		var event IssueCommentEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to parse %s event: %w", name, err)
+		}
+		return m.HandleIssueComment(ctx, event, opts)
+	default:
+		log.Info("Ignoring unsupported event: %s", name)
+		return nil
+	}
+}
+
+// HandleIssueComment handles the /assign, /take and /unassign commands in a new
+// comment on an onboarding issue, which assign or unassign the commenter. A
+// contributor can claim at most opts.MaxClaims open onboarding issues at once.
+func (m *Manager) HandleIssueComment(ctx context.Context, event IssueCommentEvent, opts onboard.Options) error {
+	if event.Action != "created" || event.Issue.PullRequest != nil || event.Issue.State != "open" || event.Comment.User.IsBot() {
+		log.Debug("Ignoring comment event (action: %s, state: %s)", event.Action, event.Issue.State)
+		return nil
+	}
+
+	command := parseCommand(event.Comment.Body)
+	if command == "" {
+		log.Debug("No command found in comment")
+		return nil
+	}
+	if !hasAnyLabel(event, opts.OnboardLabels) {
+		log.Info("Issue #%d has none of the onboarding labels [%s], ignoring %s", event.Issue.Number, strings.Join(opts.OnboardLabels, ", "), command)
+		return nil
+	}
+
+	repoName := event.Repository.FullName
+	number := event.Issue.Number
+	login := event.Comment.User.Login
+	log.Info("Handling %s from @%s on %s#%d", command, login, repoName, number)
+
+	if command == CommandUnassign {
+		if !isAssigned(event, login) {
+			log.Info("@%s is not assigned to issue #%d", login, number)
+			return nil
+		}
+		if opts.DryRun {
+			log.Warn("Dry-run mode, skipping unassigning @%s", login)
+			return nil
+		}
+		if err := m.setAssignee(repoName, number, login, "DELETE"); err != nil {
+			return err
+		}
+		log.Success("Unassigned @%s from issue #%d", login, number)
+		return m.comment(repoName, number, fmt.Sprintf("@%s has been unassigned, this issue is open to other contributors again. Thanks! 🙏", login))
+	}
+
+	// Assign the commenter if the issue is free and they are below the cap
+	var reply string
+	switch {
+	case isAssigned(event, login):
+		log.Info("@%s is already assigned to issue #%d", login, number)
+		return nil
+	case len(event.Issue.Assignees) > 0:
+		reply = fmt.Sprintf("Thanks for your interest @%s! This issue is already assigned to @%s, "+
+			"feel free to pick another one or to ask here whether help is welcome.", login, event.Issue.Assignees[0].Login)
+	default:
+		claims, err := m.countClaims(ctx, repoName, login, opts)
+		if err != nil {
+			return err
+		}
+		log.Debug("@%s has claimed %d onboarding issues", login, claims)
+		if opts.MaxClaims > 0 && claims >= opts.MaxClaims {
+			reply = fmt.Sprintf("Thanks for your interest @%s! You are already working on %d onboarding issues, "+
+				"which is the limit for now. Please finish or `/unassign` one of them before taking another, "+
+				"so that other newcomers get a chance too. 🙏", login, claims)
+		}
+	}
+
+	if reply != "" {
+		log.Info("Not assigning @%s to issue #%d", login, number)
+		if opts.DryRun {
+			log.Warn("Dry-run mode, skipping reply:").L(1).P("→").Log("%s", reply)
+			return nil
+		}
+		return m.comment(repoName, number, reply)
+	}
+	if opts.DryRun {
+		log.Warn("Dry-run mode, skipping assigning @%s", login)
+		return nil
+	}
+	if err := m.setAssignee(repoName, number, login, "POST"); err != nil {
+		return err
+	}
+	log.Success("Assigned @%s to issue #%d", login, number)
+	return m.comment(repoName, number, fmt.Sprintf("Thanks @%s, this issue is now assigned to you! 🎉 "+
+		"If you need help, feel free to ask here. Comment `/unassign` if you can't work on it anymore.", login))
+}
+
+// parseCommand returns the first command found at the start of a line of the
+// comment, or an empty command if there is none
+func parseCommand(body string) Command {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch command := Command(strings.ToLower(fields[0])); command {
+		case CommandAssign, CommandTake:
+			return CommandAssign
+		case CommandUnassign:
+			return CommandUnassign
+		}
+	}
+	return ""
+}
+
+// hasAnyLabel reports whether the issue of the event has any of the labels
+func hasAnyLabel(event IssueCommentEvent, labels []string) bool {
+	for _, issueLabel := range event.Issue.Labels {
+		for _, label := range labels {
+			if strings.EqualFold(issueLabel.Name, label) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAssigned reports whether the issue of the event is assigned to the user
+func isAssigned(event IssueCommentEvent, login string) bool {
+	for _, assignee := range event.Issue.Assignees {
+		if strings.EqualFold(assignee.Login, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// countClaims returns the number of open onboarding issues assigned to the user
+func (m *Manager) countClaims(_ context.Context, repoName, login string, opts onboard.Options) (int, error) {
+	query := fmt.Sprintf("repo:%s is:issue is:open assignee:%s", repoName, login)
+	if len(opts.OnboardLabels) > 0 {
+		quoted := make([]string, 0, len(opts.OnboardLabels))
+		for _, label := range opts.OnboardLabels {
+			quoted = append(quoted, fmt.Sprintf("\"%s\"", label))
+		}
+		query += " label:" + strings.Join(quoted, ",")
+	}
+	log.Debug("Search query: %s", query)
+
+	var response struct {
+		TotalCount int `json:"total_count"`
+	}
+	if err := m.client.Get(fmt.Sprintf("search/issues?q=%s&per_page=1", url.QueryEscape(query)), &response); err != nil {
+		return 0, fmt.Errorf("failed to search issues assigned to @%s: %w", login, err)
+	}
+	return response.TotalCount, nil
+}
+
+// setAssignee adds (POST) or removes (DELETE) the user from the assignees of the issue
+func (m *Manager) setAssignee(repoName string, number int, login, method string) error {
+	bodyBytes, err := json.Marshal(map[string]interface{}{"assignees": []string{login}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	path := fmt.Sprintf("repos/%s/issues/%d/assignees", repoName, number)
+	var issue struct {
+		Assignees []User `json:"assignees"`
+	}
+	if err := m.client.Do(method, path, bytes.NewReader(bodyBytes), &issue); err != nil {
+		return fmt.Errorf("failed to update assignees of issue #%d: %w", number, err)
+	}
+
+	// GitHub silently ignores the users who cannot be assigned
+	if method == "POST" {
+		for _, assignee := range issue.Assignees {
+			if strings.EqualFold(assignee.Login, login) {
+				return nil
+			}
+		}
+		return fmt.Errorf("@%s cannot be assigned to issue #%d", login, number)
+	}
+	return nil
+}
+
+// comment posts a comment on an issue
+func (m *Manager) comment(repoName string, number int, body string) error {
+	bodyBytes, err := json.Marshal(map[string]interface{}{"body": body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	path := fmt.Sprintf("repos/%s/issues/%d/comments", repoName, number)
+	if err := m.client.Post(path, bytes.NewReader(bodyBytes), nil); err != nil {
+		return fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+	}
+	return nil
+}
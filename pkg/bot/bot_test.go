@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/onboard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTransport sends the requests to the GitHub API to a test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestManager returns a manager whose GitHub API requests are handled by the handler
+func newTestManager(t *testing.T, handler http.HandlerFunc) *Manager {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: redirectTransport{target: target}})
+	require.NoError(t, err)
+	return NewManager(client)
+}
+
+// newCommentEvent returns a comment event of bob on an open onboarding issue
+func newCommentEvent(t *testing.T, assignees, body string) IssueCommentEvent {
+	var event IssueCommentEvent
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"action": "created",
+		"issue": {"number": 1, "state": "open", "labels": [{"name": "good first issue"}], "assignees": `+assignees+`},
+		"comment": {"body": "`+body+`", "user": {"login": "bob"}},
+		"repository": {"full_name": "owner/repo"}
+	}`), &event))
+	return event
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		body string
+		want Command
+	}{
+		{body: "/assign", want: CommandAssign},
+		{body: "I'd like to work on this!\n\n/take", want: CommandAssign},
+		{body: "  /Assign please", want: CommandAssign},
+		{body: "/unassign", want: CommandUnassign},
+		{body: "Please run /assign", want: ""},
+		{body: "/assignee", want: ""},
+		{body: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseCommand(tt.body))
+		})
+	}
+}
+
+func TestIssueCommentEvent(t *testing.T) {
+	var event IssueCommentEvent
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"action": "created",
+		"issue": {
+			"number": 12,
+			"state": "open",
+			"labels": [{"name": "Good First Issue"}],
+			"assignees": [{"login": "alice"}]
+		},
+		"comment": {"body": "/take", "user": {"login": "bob", "type": "User"}},
+		"repository": {"full_name": "owner/repo"}
+	}`), &event))
+
+	assert.Equal(t, 12, event.Issue.Number)
+	assert.Equal(t, "owner/repo", event.Repository.FullName)
+	assert.True(t, hasAnyLabel(event, []string{"help wanted", "good first issue"}))
+	assert.False(t, hasAnyLabel(event, []string{"help wanted"}))
+	assert.True(t, isAssigned(event, "Alice"))
+	assert.False(t, isAssigned(event, "bob"))
+	assert.False(t, event.Comment.User.IsBot())
+	assert.True(t, User{Login: "github-actions[bot]"}.IsBot())
+}
+
+func TestHandleEventWithoutChanges(t *testing.T) {
+	m := &Manager{}
+	opts := onboard.DefaultOptions()
+	opts.DryRun = true
+	ctx := context.Background()
+
+	newPayload := func(labels, assignees, body string) []byte {
+		return []byte(`{
+			"action": "created",
+			"issue": {"number": 1, "state": "open", "labels": ` + labels + `, "assignees": ` + assignees + `},
+			"comment": {"body": "` + body + `", "user": {"login": "bob"}},
+			"repository": {"full_name": "owner/repo"}
+		}`)
+	}
+
+	// None of these reach the GitHub API
+	assert.NoError(t, m.HandleEvent(ctx, "issues", []byte(`{}`), opts))
+	assert.NoError(t, m.HandleEvent(ctx, EventIssueComment, newPayload(`[{"name": "bug"}]`, `[]`, "/assign"), opts))
+	assert.NoError(t, m.HandleEvent(ctx, EventIssueComment, newPayload(`[{"name": "help wanted"}]`, `[]`, "LGTM"), opts))
+	assert.NoError(t, m.HandleEvent(ctx, EventIssueComment, newPayload(`[{"name": "help wanted"}]`, `[{"login": "bob"}]`, "/take"), opts))
+	assert.NoError(t, m.HandleEvent(ctx, EventIssueComment, newPayload(`[{"name": "help wanted"}]`, `[]`, "/unassign"), opts))
+	assert.NoError(t, m.HandleEvent(ctx, EventIssueComment, newPayload(`[{"name": "help wanted"}]`, `[{"login": "alice"}]`, "/assign"), opts))
+	assert.Error(t, m.HandleEvent(ctx, EventIssueComment, []byte(`{`), opts))
+}
+
+func TestHandleIssueComment(t *testing.T) {
+	tests := []struct {
+		name      string
+		assignees string
+		body      string
+		claims    int
+		assigned  string // Response of the assignees endpoint
+		want      []string
+		wantReply string
+		wantErr   string
+	}{
+		{
+			name:      "assign",
+			assignees: `[]`,
+			body:      "/assign",
+			claims:    1,
+			assigned:  `{"assignees": [{"login": "bob"}]}`,
+			want:      []string{"GET /search/issues", "POST /repos/owner/repo/issues/1/assignees", "POST /repos/owner/repo/issues/1/comments"},
+			wantReply: "this issue is now assigned to you",
+		},
+		{
+			name:      "unassign",
+			assignees: `[{"login": "bob"}]`,
+			body:      "/unassign",
+			assigned:  `{"assignees": []}`,
+			want:      []string{"DELETE /repos/owner/repo/issues/1/assignees", "POST /repos/owner/repo/issues/1/comments"},
+			wantReply: "@bob has been unassigned",
+		},
+		{
+			name:      "claims at the cap",
+			assignees: `[]`,
+			body:      "/take",
+			claims:    2,
+			want:      []string{"GET /search/issues", "POST /repos/owner/repo/issues/1/comments"},
+			wantReply: "already working on 2 onboarding issues",
+		},
+		{
+			name:      "claims above the cap",
+			assignees: `[]`,
+			body:      "/take",
+			claims:    3,
+			want:      []string{"GET /search/issues", "POST /repos/owner/repo/issues/1/comments"},
+			wantReply: "already working on 3 onboarding issues",
+		},
+		{
+			name:      "user cannot be assigned",
+			assignees: `[]`,
+			body:      "/assign",
+			assigned:  `{"assignees": []}`,
+			want:      []string{"GET /search/issues", "POST /repos/owner/repo/issues/1/assignees"},
+			wantErr:   "@bob cannot be assigned to issue #1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			var reply string
+			m := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				switch r.URL.Path {
+				case "/search/issues":
+					assert.Contains(t, r.URL.Query().Get("q"), "assignee:bob")
+					_ = json.NewEncoder(w).Encode(map[string]int{"total_count": tt.claims})
+				case "/repos/owner/repo/issues/1/assignees":
+					body, _ := io.ReadAll(r.Body)
+					assert.JSONEq(t, `{"assignees": ["bob"]}`, string(body))
+					_, _ = w.Write([]byte(tt.assigned))
+				case "/repos/owner/repo/issues/1/comments":
+					var comment struct {
+						Body string `json:"body"`
+					}
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&comment))
+					reply = comment.Body
+					_, _ = w.Write([]byte(`{}`))
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			})
+
+			err := m.HandleIssueComment(context.Background(), newCommentEvent(t, tt.assignees, tt.body), onboard.DefaultOptions())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, requests)
+			if tt.wantReply == "" {
+				assert.Empty(t, reply)
+			} else {
+				assert.Contains(t, reply, tt.wantReply)
+			}
+		})
+	}
+}
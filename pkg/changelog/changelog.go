@@ -0,0 +1,423 @@
+package changelog
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/util/gitutil"
+	"github.com/elliotxx/osp/pkg/util/prompt"
+)
+
+//go:embed templates/changelog.gotmpl
+var templates embed.FS
+
+// header is the title of a changelog file, kept at the top when prepending sections
+const header = "# Changelog"
+
+// ErrNoRelease is returned when the commits since the latest version do not
+// require a new release, e.g. when there are only documentation changes
+var ErrNoRelease = errors.New("no release needed")
+
+var (
+	// headerPattern matches the header of a conventional commit, e.g. "feat(cli)!: add flag"
+	headerPattern = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+	// versionPattern matches a semantic version with an optional "v" prefix
+	versionPattern = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(-[^+]*)?(?:\+.*)?$`)
+)
+
+// typeTitles are the section titles of the commit types
+var typeTitles = map[string]string{
+	"feat":     "Features",
+	"fix":      "Bug Fixes",
+	"perf":     "Performance Improvements",
+	"revert":   "Reverts",
+	"refactor": "Code Refactoring",
+	"docs":     "Documentation",
+	"style":    "Styles",
+	"test":     "Tests",
+	"build":    "Build System",
+	"ci":       "Continuous Integration",
+	"chore":    "Miscellaneous Chores",
+}
+
+// Commit represents a conventional commit
+type Commit struct {
+	SHA          string
+	Type         string
+	Scope        string
+	Description  string
+	Breaking     bool
+	BreakingNote string // Text of the BREAKING CHANGE footer, if any
+}
+
+// ShortSHA returns the abbreviated SHA of the commit
+func (c Commit) ShortSHA() string {
+	if len(c.SHA) > 7 {
+		return c.SHA[:7]
+	}
+	return c.SHA
+}
+
+// BreakingDescription returns the description of the breaking change, which is
+// the BREAKING CHANGE footer if present, or the commit description otherwise
+func (c Commit) BreakingDescription() string {
+	if c.BreakingNote != "" {
+		return c.BreakingNote
+	}
+	return c.Description
+}
+
+// ParseCommit parses a commit message as a conventional commit. It returns
+// false if the message does not follow the convention.
+func ParseCommit(sha, message string) (Commit, bool) {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	match := headerPattern.FindStringSubmatch(strings.TrimSpace(subject))
+	if match == nil {
+		return Commit{}, false
+	}
+
+	commit := Commit{
+		SHA:         sha,
+		Type:        strings.ToLower(match[1]),
+		Scope:       match[2],
+		Description: strings.TrimSpace(match[4]),
+		Breaking:    match[3] == "!",
+	}
+
+	// Look for the breaking change footer, which may span several lines
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		for _, token := range []string{"BREAKING CHANGE:", "BREAKING-CHANGE:"} {
+			if note, ok := strings.CutPrefix(line, token); ok {
+				commit.Breaking = true
+				notes := []string{strings.TrimSpace(note)}
+				for _, next := range lines[i+1:] {
+					if strings.TrimSpace(next) == "" {
+						break
+					}
+					notes = append(notes, strings.TrimSpace(next))
+				}
+				commit.BreakingNote = strings.TrimSpace(strings.Join(notes, " "))
+			}
+		}
+	}
+
+	return commit, true
+}
+
+// Bump represents a semantic version increment
+type Bump int
+
+// Semantic version increments, ordered from the smallest to the largest
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+// String returns the name of the increment
+func (b Bump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// SuggestBump returns the increment implied by the commits: major for breaking
+// changes, minor for features and patch for fixes
+func SuggestBump(commits []Commit) Bump {
+	bump := BumpNone
+	for _, commit := range commits {
+		switch {
+		case commit.Breaking:
+			return BumpMajor
+		case commit.Type == "feat":
+			bump = max(bump, BumpMinor)
+		case commit.Type == "fix":
+			bump = max(bump, BumpPatch)
+		}
+	}
+	return bump
+}
+
+// NextVersion increments the version, keeping its "v" prefix and dropping any
+// pre-release or build metadata. Without an increment, a pre-release becomes
+// its final release and ErrNoRelease is returned for any other version.
+func NextVersion(version string, bump Bump) (string, error) {
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return "", fmt.Errorf("invalid semantic version '%s'", version)
+	}
+	if bump == BumpNone && match[5] == "" {
+		return "", fmt.Errorf("%w since %s", ErrNoRelease, version)
+	}
+	major, _ := strconv.Atoi(match[2])
+	minor, _ := strconv.Atoi(match[3])
+	patch, _ := strconv.Atoi(match[4])
+
+	switch bump {
+	case BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case BumpMinor:
+		minor, patch = minor+1, 0
+	case BumpPatch:
+		patch++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", match[1], major, minor, patch), nil
+}
+
+// Options represents changelog options
+type Options struct {
+	From        string   // Tag the changelog starts after, defaults to the latest tag
+	Version     string   // Version of the section, defaults to the suggested next version
+	Types       []string // Commit types listed in the changelog, breaking changes are always listed
+	File        string   // Path of the changelog file the section is prepended to
+	DryRun      bool     // If true, only show preview without making changes
+	AutoConfirm bool     // If true, skip confirmation prompt
+}
+
+// DefaultOptions returns default changelog options
+func DefaultOptions() Options {
+	return Options{
+		Types:       []string{"feat", "fix", "perf", "revert"},
+		File:        "CHANGELOG.md",
+		DryRun:      false,
+		AutoConfirm: false,
+	}
+}
+
+// Section represents the commits of a type
+type Section struct {
+	Type    string
+	Title   string
+	Commits []Commit
+}
+
+// TemplateData represents the data passed to the template
+type TemplateData struct {
+	Version         string
+	PreviousVersion string // Empty if there is no tag yet
+	Date            time.Time
+	Bump            Bump
+	Sections        []Section
+	BreakingChanges []Commit
+}
+
+// Generate renders the changelog section of the commits since the latest tag
+func Generate(opts Options) (TemplateData, string, error) {
+	data := TemplateData{Date: time.Now()}
+
+	from := opts.From
+	if from == "" {
+		// The history has no tag yet if this fails, so all commits are included
+		if tag, err := gitutil.GetLatestTag(); err == nil {
+			from = tag
+		} else {
+			log.Debug("No tag found, including the whole history")
+		}
+	}
+	data.PreviousVersion = from
+
+	gitCommits, err := gitutil.GetCommitsSince(from)
+	if err != nil {
+		return data, "", fmt.Errorf("failed to read git history: %w", err)
+	}
+	var commits []Commit
+	for _, c := range gitCommits {
+		commit, ok := ParseCommit(c.SHA, c.Message)
+		if !ok {
+			log.Debug("Skipping non-conventional commit %.7s", c.SHA)
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	log.Debug("Parsed %d conventional commits out of %d commits", len(commits), len(gitCommits))
+	if len(commits) == 0 {
+		if from == "" {
+			return data, "", errors.New("no conventional commits found")
+		}
+		return data, "", fmt.Errorf("no conventional commits found since %s", from)
+	}
+
+	data.Bump = SuggestBump(commits)
+	data.Version = opts.Version
+	if data.Version == "" {
+		current := from
+		if current == "" {
+			current = "v0.0.0"
+		}
+		data.Version, err = NextVersion(current, data.Bump)
+		if errors.Is(err, ErrNoRelease) {
+			return data, "", err
+		}
+		if err != nil {
+			return data, "", fmt.Errorf("failed to suggest the next version, specify it explicitly: %w", err)
+		}
+	}
+	data.Sections, data.BreakingChanges = groupCommits(commits, opts.Types)
+
+	content, err := generateContent(data)
+	if err != nil {
+		return data, "", fmt.Errorf("failed to generate changelog: %w", err)
+	}
+	return data, content, nil
+}
+
+// Update generates the changelog section and prepends it to the changelog file
+// after confirmation
+func Update(opts Options) error {
+	data, content, err := Generate(opts)
+	if errors.Is(err, ErrNoRelease) {
+		log.Success("No features or fixes found, %v", err).
+			L(1).P("→").Log("Use --next-version to generate the changelog anyway")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if data.PreviousVersion == "" {
+		log.Info("Suggested version: %s (first release)", data.Version)
+	} else {
+		log.Info("Suggested version: %s (%s bump from %s)", data.Version, data.Bump, data.PreviousVersion)
+	}
+
+	// Preview the content
+	log.C(log.ColorBlue).P("↓").Log("Preview of the changelog section:")
+	log.C(log.ColorCyan).Log("%s", content)
+
+	existing, err := os.ReadFile(opts.File)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", opts.File, err)
+	}
+	if hasVersion(string(existing), data.Version) {
+		log.Success("%s already contains version %s, skipping update", opts.File, data.Version)
+		return nil
+	}
+
+	if opts.DryRun {
+		log.Warn("Dry-run mode, skipping update")
+		return nil
+	}
+
+	// Ask for confirmation if auto-confirm is not enabled
+	if !opts.AutoConfirm {
+		log.Info("Will prepend the above section to %s", opts.File)
+
+		confirmed, err := prompt.AskForConfirmation("Do you want to proceed with the update?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info("Update cancelled")
+			return nil
+		}
+	} else {
+		log.Warn("Auto-confirm is enabled, skipping confirmation")
+	}
+
+	if err := os.WriteFile(opts.File, []byte(prepend(string(existing), content)), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.File, err)
+	}
+	log.Success("Successfully prepended version %s to %s", data.Version, opts.File)
+
+	return nil
+}
+
+// groupCommits groups the commits of the types into sections in the order of
+// the types, sorted by scope, and collects the breaking changes of all types
+func groupCommits(commits []Commit, types []string) ([]Section, []Commit) {
+	var breaking []Commit
+	byType := make(map[string][]Commit)
+	for _, commit := range commits {
+		if commit.Breaking {
+			breaking = append(breaking, commit)
+		}
+		byType[commit.Type] = append(byType[commit.Type], commit)
+	}
+
+	var sections []Section
+	for _, t := range types {
+		t = strings.ToLower(t)
+		if len(byType[t]) == 0 {
+			continue
+		}
+		title, ok := typeTitles[t]
+		if !ok {
+			title = t
+		}
+		sorted := byType[t]
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Scope < sorted[j].Scope
+		})
+		sections = append(sections, Section{Type: t, Title: title, Commits: sorted})
+	}
+	return sections, breaking
+}
+
+// hasVersion reports whether the changelog already has a section for the version
+func hasVersion(changelog, version string) bool {
+	for _, line := range strings.Split(changelog, "\n") {
+		title, ok := strings.CutPrefix(line, "## ")
+		if !ok {
+			continue
+		}
+		// The version may be a link, e.g. "## [v0.1.0](https://...)"
+		title = strings.TrimPrefix(title, "[")
+		if end := strings.IndexAny(title, "] "); end >= 0 {
+			title = title[:end]
+		}
+		if title == version {
+			return true
+		}
+	}
+	return false
+}
+
+// prepend inserts the section before the existing sections of the changelog,
+// below its title and introduction
+func prepend(changelog, section string) string {
+	section = strings.TrimSpace(section) + "\n"
+	if strings.TrimSpace(changelog) == "" {
+		return header + "\n\n" + section
+	}
+	if idx := strings.Index(changelog, "\n## "); idx >= 0 {
+		return changelog[:idx+1] + section + "\n" + changelog[idx+1:]
+	}
+	if strings.HasPrefix(changelog, "## ") {
+		return section + "\n" + changelog
+	}
+	return strings.TrimRight(changelog, "\n") + "\n\n" + section
+}
+
+// generateContent renders the changelog section using the built-in template
+func generateContent(data TemplateData) (string, error) {
+	tmpl, err := template.New("changelog.gotmpl").ParseFS(templates, "templates/changelog.gotmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.String(), nil
+}
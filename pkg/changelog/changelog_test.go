@@ -0,0 +1,131 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Commit
+		ok      bool
+	}{
+		{
+			name:    "type only",
+			message: "fix: handle empty milestones",
+			want:    Commit{SHA: "abc", Type: "fix", Description: "handle empty milestones"},
+			ok:      true,
+		},
+		{
+			name:    "scope and bang",
+			message: "feat(cli)!: rename flags",
+			want:    Commit{SHA: "abc", Type: "feat", Scope: "cli", Description: "rename flags", Breaking: true},
+			ok:      true,
+		},
+		{
+			name:    "breaking change footer",
+			message: "refactor: drop config v1\n\nMore context.\n\nBREAKING CHANGE: the v1 config\nis no longer read",
+			want: Commit{
+				SHA: "abc", Type: "refactor", Description: "drop config v1",
+				Breaking: true, BreakingNote: "the v1 config is no longer read",
+			},
+			ok: true,
+		},
+		{
+			name:    "not conventional",
+			message: "Update README.md",
+			ok:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseCommit("abc", tt.message)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSuggestBumpAndNextVersion(t *testing.T) {
+	assert.Equal(t, BumpNone, SuggestBump([]Commit{{Type: "docs"}}))
+	assert.Equal(t, BumpPatch, SuggestBump([]Commit{{Type: "docs"}, {Type: "fix"}}))
+	assert.Equal(t, BumpMinor, SuggestBump([]Commit{{Type: "fix"}, {Type: "feat"}}))
+	assert.Equal(t, BumpMajor, SuggestBump([]Commit{{Type: "feat"}, {Type: "chore", Breaking: true}}))
+
+	tests := []struct {
+		version string
+		bump    Bump
+		want    string
+	}{
+		{"v0.3.1", BumpMajor, "v1.0.0"},
+		{"v0.3.1", BumpMinor, "v0.4.0"},
+		{"0.3.1", BumpPatch, "0.3.2"},
+		{"v1.2.0-rc.1", BumpNone, "v1.2.0"},
+		{"v1.2.0-rc.1+build.5", BumpNone, "v1.2.0"},
+		{"v1.2.0+build.5", BumpPatch, "v1.2.1"},
+	}
+	for _, tt := range tests {
+		got, err := NextVersion(tt.version, tt.bump)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := NextVersion("latest", BumpMinor)
+	assert.Error(t, err)
+
+	// Without features or fixes, only a pre-release needs a release
+	_, err = NextVersion("v1.2.0", BumpNone)
+	assert.ErrorIs(t, err, ErrNoRelease)
+	_, err = NextVersion("v1.2.0+build.5", BumpNone)
+	assert.ErrorIs(t, err, ErrNoRelease)
+}
+
+func TestGenerateContent(t *testing.T) {
+	commits := []Commit{
+		{SHA: "1111111aaa", Type: "feat", Scope: "stats", Description: "add charts"},
+		{SHA: "2222222bbb", Type: "fix", Description: "handle empty milestones"},
+		{SHA: "3333333ccc", Type: "feat", Description: "add digest", Breaking: true, BreakingNote: "digest label changed"},
+		{SHA: "4444444ddd", Type: "chore", Description: "bump deps"},
+	}
+	sections, breaking := groupCommits(commits, DefaultOptions().Types)
+	data := TemplateData{
+		Version:         "v1.0.0",
+		Date:            time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		Sections:        sections,
+		BreakingChanges: breaking,
+	}
+
+	content, err := generateContent(data)
+	assert.NoError(t, err)
+	assert.Equal(t, `## v1.0.0 (2025-01-02)
+
+### ⚠ BREAKING CHANGES
+- digest label changed (3333333)
+
+### Features
+- add digest (3333333)
+- **stats:** add charts (1111111)
+
+### Bug Fixes
+- handle empty milestones (2222222)
+`, content)
+}
+
+func TestPrepend(t *testing.T) {
+	section := "## v0.2.0 (2025-02-01)\n\n### Features\n- b\n"
+
+	assert.Equal(t, "# Changelog\n\n"+section, prepend("", section))
+	assert.Equal(t,
+		"# Changelog\n\nAll notable changes.\n\n"+section+"\n## v0.1.0\n- a\n",
+		prepend("# Changelog\n\nAll notable changes.\n\n## v0.1.0\n- a\n", section))
+	assert.Equal(t, section+"\n## v0.1.0\n", prepend("## v0.1.0\n", section))
+
+	assert.True(t, hasVersion("# Changelog\n\n## v0.1.0 (2025-01-01)\n", "v0.1.0"))
+	assert.True(t, hasVersion("## [v0.1.0](https://example.com)\n", "v0.1.0"))
+	assert.False(t, hasVersion("## v0.1.0\n", "v0.1.1"))
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/auth"
+	"github.com/elliotxx/osp/pkg/bot"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/onboard"
+	"github.com/spf13/cobra"
+)
+
+var botCmd = &cobra.Command{
+	Use:   "bot",
+	Short: "Handle GitHub events for the repository",
+	Long: `Handle a GitHub event, typically from a GitHub Actions workflow.
+
+The event name and payload are read from the GITHUB_EVENT_NAME and GITHUB_EVENT_PATH
+environment variables set by GitHub Actions, or from --event-name and --event-path.
+
+Supported events:
+  issue_comment - On an issue with one of the onboarding labels, a comment starting
+                  with /assign or /take assigns the commenter, and /unassign
+                  unassigns them. A contributor can claim at most --max-claims
+                  open onboarding issues at once.
+
+Examples:
+  # Handle the event of the current GitHub Actions workflow
+  osp bot
+
+  # Handle a saved event payload
+  osp bot --event-name issue_comment --event-path event.json
+
+  # Allow contributors to claim up to 3 onboarding issues at once
+  osp bot --max-claims 3
+
+  # Show what would be done without modifying any issues
+  osp bot --dry-run`,
+	RunE: runBot,
+}
+
+func runBot(cmd *cobra.Command, _ []string) error {
+	// Check authentication
+	if err := auth.CheckAuth(); err != nil {
+		return err
+	}
+
+	// Get flags
+	eventName, err := cmd.Flags().GetString("event-name")
+	if err != nil {
+		return err
+	}
+	eventPath, err := cmd.Flags().GetString("event-path")
+	if err != nil {
+		return err
+	}
+	onboardLabels, err := cmd.Flags().GetStringSlice("onboard-labels")
+	if err != nil {
+		return err
+	}
+	maxClaims, err := cmd.Flags().GetInt("max-claims")
+	if err != nil {
+		return err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	if eventName == "" {
+		eventName = os.Getenv("GITHUB_EVENT_NAME")
+	}
+	if eventPath == "" {
+		eventPath = os.Getenv("GITHUB_EVENT_PATH")
+	}
+	if eventName == "" || eventPath == "" {
+		return fmt.Errorf("no event to handle, use --event-name and --event-path outside of GitHub Actions")
+	}
+	log.Debug("Handling %s event from %s", eventName, eventPath)
+
+	payload, err := os.ReadFile(eventPath)
+	if err != nil {
+		return fmt.Errorf("failed to read event payload: %w", err)
+	}
+
+	// Create GitHub client
+	client, err := api.DefaultRESTClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	// Create options
+	opts := onboard.DefaultOptions()
+	opts.OnboardLabels = onboardLabels
+	opts.MaxClaims = maxClaims
+	opts.DryRun = dryRun
+
+	if err := bot.NewManager(client).HandleEvent(cmd.Context(), eventName, payload, opts); err != nil {
+		return fmt.Errorf("failed to handle %s event: %w", eventName, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(botCmd)
+
+	// Add flags
+	botCmd.Flags().String("event-name", "", "Name of the GitHub event to handle, defaults to $GITHUB_EVENT_NAME")
+	botCmd.Flags().String("event-path", "", "Path of the JSON payload of the GitHub event, defaults to $GITHUB_EVENT_PATH")
+	botCmd.Flags().StringSliceP("onboard-labels", "o", onboard.DefaultOptions().OnboardLabels, "Labels of the issues contributors can assign themselves to (e.g., 'good first issue', 'help wanted')")
+	botCmd.Flags().Int("max-claims", onboard.DefaultOptions().MaxClaims, "Maximum number of open onboarding issues a contributor can claim at once, 0 for no limit")
+	botCmd.Flags().BoolP("dry-run", "n", false, "Show what would be done without modifying any issues")
+}
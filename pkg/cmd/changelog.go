@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"github.com/elliotxx/osp/pkg/changelog"
+	"github.com/spf13/cobra"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate a changelog from conventional commits",
+	Long: `Generate a changelog section from the local git history.
+
+This command reads the commits since the latest tag of the current directory,
+parses them as conventional commits (type(scope)!: description), and renders a
+changelog section with the breaking changes and a section per commit type.
+
+It also suggests the next semantic version: major for breaking changes ('!' or
+a BREAKING CHANGE footer), minor for features and patch for fixes. Without any
+feature or fix, no release is needed and nothing is generated unless
+--next-version is specified. After a preview, the section is prepended to the
+changelog file upon confirmation.
+
+Examples:
+  # Preview the changelog since the latest tag and prepend it to CHANGELOG.md
+  osp changelog
+
+  # Generate the changelog since a tag with an explicit version
+  osp changelog --from v0.3.0 --next-version v0.4.0
+
+  # Include more commit types
+  osp changelog --types feat,fix,perf,revert,docs,refactor
+
+  # Preview without modifying CHANGELOG.md
+  osp changelog --dry-run`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		var err error
+		opts := changelog.DefaultOptions()
+		if opts.From, err = cmd.Flags().GetString("from"); err != nil {
+			return err
+		}
+		if opts.Version, err = cmd.Flags().GetString("next-version"); err != nil {
+			return err
+		}
+		if opts.Types, err = cmd.Flags().GetStringSlice("types"); err != nil {
+			return err
+		}
+		if opts.File, err = cmd.Flags().GetString("file"); err != nil {
+			return err
+		}
+		if opts.DryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+			return err
+		}
+		if opts.AutoConfirm, err = cmd.Flags().GetBool("yes"); err != nil {
+			return err
+		}
+
+		return changelog.Update(opts)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	// Add flags
+	changelogCmd.Flags().String("from", "", "Tag the changelog starts after (default: the latest tag)")
+	changelogCmd.Flags().String("next-version", "", "Version of the changelog section (default: the suggested next version)")
+	changelogCmd.Flags().StringSlice("types", changelog.DefaultOptions().Types, "Commit types listed in the changelog, breaking changes are always listed")
+	changelogCmd.Flags().StringP("file", "f", changelog.DefaultOptions().File, "Path of the changelog file to prepend the section to")
+	changelogCmd.Flags().BoolP("dry-run", "n", changelog.DefaultOptions().DryRun, "Preview the changelog without modifying the file")
+	changelogCmd.Flags().BoolP("yes", "y", changelog.DefaultOptions().AutoConfirm, "Automatically prepend the section without confirmation")
+}
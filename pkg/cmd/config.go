@@ -51,6 +51,7 @@ and clean up configuration files.`,
 This will remove:
 - Configuration files (~/.config/osp/*)
 - State data (~/.local/state/osp/*)
+- Persistent data such as statistics snapshots (~/.local/share/osp/*)
 
 Note: This action cannot be undone!`,
 		RunE: runConfigClean,
@@ -71,16 +72,19 @@ func runConfigList(cmd *cobra.Command, args []string) error {
 	// Get XDG paths from config package
 	configHome := config.GetConfigHome()
 	stateHome := config.GetStateHome()
+	dataHome := config.GetDataHome()
 
 	// Get OSP paths
 	configDir := config.GetConfigDir()
 	configFile := config.GetConfigFile()
 	stateDir := config.GetStateDir()
+	dataDir := config.GetDataDir()
 
 	// Print XDG environment variables
 	log.B().Log("XDG Base Directories:")
 	log.L(1).Info("%-16s = %s", "XDG_CONFIG_HOME", configHome)
 	log.L(1).Info("%-16s = %s", "XDG_STATE_HOME", stateHome)
+	log.L(1).Info("%-16s = %s", "XDG_DATA_HOME", dataHome)
 
 	// Print OSP locations
 	log.B().Log("\nOSP Locations:")
@@ -108,6 +112,14 @@ func runConfigList(cmd *cobra.Command, args []string) error {
 		log.L(2).Error("%-12s %v", "Exists:", false)
 	}
 
+	log.L(1).Info("Data Directory:")
+	log.L(2).Info("%-12s %s", "Path:", dataDir)
+	if fileExists(dataDir) {
+		log.L(2).Success("%-12s %v", "Exists:", true)
+	} else {
+		log.L(2).Error("%-12s %v", "Exists:", false)
+	}
+
 	return nil
 }
 
@@ -149,14 +161,16 @@ func runConfigClean(cmd *cobra.Command, args []string) error {
 	configDir := config.GetConfigDir()
 	configFile := config.GetConfigFile()
 
-	// Get data directory
+	// Get state and data directories
 	stateDir := config.GetStateDir()
+	dataDir := config.GetDataDir()
 
 	// Print locations
 	log.Info("The following files and directories will be removed:")
 	log.L(1).Info("Config directory: %s", configDir)
 	log.L(2).Info("Config file: %s", configFile)
 	log.L(1).Info("State directory: %s", stateDir)
+	log.L(1).Info("Data directory: %s", dataDir)
 
 	// Check which directories exist
 	var existingDirs []string
@@ -166,6 +180,9 @@ func runConfigClean(cmd *cobra.Command, args []string) error {
 	if _, err := os.Stat(stateDir); err == nil {
 		existingDirs = append(existingDirs, stateDir)
 	}
+	if _, err := os.Stat(dataDir); err == nil {
+		existingDirs = append(existingDirs, dataDir)
+	}
 
 	// Skip if nothing to clean
 	if len(existingDirs) == 0 {
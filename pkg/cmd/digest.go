@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/auth"
+	"github.com/elliotxx/osp/pkg/config"
+	"github.com/elliotxx/osp/pkg/digest"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/repo"
+	"github.com/elliotxx/osp/pkg/util/diff"
+	"github.com/spf13/cobra"
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Generate a community activity digest",
+	Long: `Generate a digest of the community activity since a given time.
+
+This command collects newly opened and closed issues, merged pull requests, new
+discussions, releases and the comments with the most reactions, and creates or
+updates an issue with the digest. With --discussion-category, the digest is
+published as a discussion in that category instead.
+
+The digest content can be rendered with a custom Go template via --template
+or the "digest_template" entry of the repository in the config file. The template
+is read from local disk, or from the repository if no such local file exists.
+
+Available fields in title template:
+  .From      - Start of the collected activity
+  .To        - End of the collected activity
+  .RepoOwner - Repository owner
+  .RepoName  - Repository name
+
+Examples:
+  # Generate the digest of the last 7 days
+  osp digest
+
+  # Generate the digest of the activity since a date
+  osp digest --since 2025-01-01
+
+  # Publish the digest as a discussion
+  osp digest --discussion-category "Announcements"
+
+  # Preview changes without updating any issues
+  osp digest --dry-run
+
+  # Update automatically without confirmation (e.g., in a scheduled workflow)
+  osp digest --yes`,
+	RunE: runDigestUpdate,
+}
+
+func runDigestUpdate(cmd *cobra.Command, _ []string) error {
+	// Check authentication
+	if err := auth.CheckAuth(); err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Get repository name
+	repoManager, err := repo.NewManager(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create repository manager: %w", err)
+	}
+	repoName := repoManager.Current()
+	if repoName == "" {
+		return fmt.Errorf("no repository selected, use 'osp repo switch' to select a repository first")
+	}
+	owner, name, ok := strings.Cut(repoName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository format: %s", repoName)
+	}
+	log.Debug("Generating digest for %s", repoName)
+
+	// Get flags
+	opts := digest.DefaultOptions()
+	if opts.Days, err = cmd.Flags().GetInt("days"); err != nil {
+		return err
+	}
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return err
+	}
+	if since != "" {
+		opts.Since, err = time.Parse("2006-01-02", since)
+		if err != nil {
+			return fmt.Errorf("invalid since date, expected YYYY-MM-DD: %w", err)
+		}
+	}
+	if opts.DigestLabel, err = cmd.Flags().GetString("target-label"); err != nil {
+		return err
+	}
+	if opts.TargetTitle, err = cmd.Flags().GetString("target-title"); err != nil {
+		return err
+	}
+	if opts.DiscussionCategory, err = cmd.Flags().GetString("discussion-category"); err != nil {
+		return err
+	}
+	if opts.NotableComments, err = cmd.Flags().GetInt("notable-comments"); err != nil {
+		return err
+	}
+	if opts.Template, err = cmd.Flags().GetString("template"); err != nil {
+		return err
+	}
+	if opts.Template == "" {
+		opts.Template = cfg.Repo(repoName).DigestTemplate
+	}
+	if opts.Diff, err = cmd.Flags().GetBool("diff"); err != nil {
+		return err
+	}
+	if opts.DryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+		return err
+	}
+	if opts.AutoConfirm, err = cmd.Flags().GetBool("yes"); err != nil {
+		return err
+	}
+
+	// Create GitHub clients
+	client, err := api.DefaultRESTClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	gqlClient, err := api.DefaultGraphQLClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub GraphQL client: %w", err)
+	}
+
+	// Update digest
+	err = digest.NewManager(client, gqlClient).Update(cmd.Context(), owner, name, opts)
+	if errors.Is(err, diff.ErrChanges) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update digest: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	// Add flags
+	digestCmd.Flags().Int("days", digest.DefaultOptions().Days, "Number of days of activity to collect")
+	digestCmd.Flags().String("since", "", "Collect the activity since a date (YYYY-MM-DD), overrides --days")
+	digestCmd.Flags().StringP("target-label", "t", digest.DefaultOptions().DigestLabel, "Label used to locate the issue where the digest will be updated")
+	digestCmd.Flags().StringP("target-title", "T", digest.DefaultOptions().TargetTitle, "Title template of the target issue or discussion. Available fields: .From, .To, .RepoOwner, .RepoName")
+	digestCmd.Flags().String("discussion-category", "", "Publish the digest as a discussion in this category instead of an issue")
+	digestCmd.Flags().Int("notable-comments", digest.DefaultOptions().NotableComments, "Maximum number of comments with the most reactions to include")
+	digestCmd.Flags().String("template", "", "Path of a custom digest template, on local disk or inside the repository (e.g., '.github/osp/digest.gotmpl')")
+	digestCmd.Flags().Bool("diff", digest.DefaultOptions().Diff, "Show the changes without modifying any issues, and exit with a non-zero status if there are any")
+	digestCmd.Flags().BoolP("dry-run", "n", digest.DefaultOptions().DryRun, "Preview the changes without modifying any issues")
+	digestCmd.Flags().BoolP("yes", "y", digest.DefaultOptions().AutoConfirm, "Automatically apply changes without confirmation")
+}
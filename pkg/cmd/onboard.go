@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/elliotxx/osp/pkg/log"
 	"github.com/elliotxx/osp/pkg/onboard"
 	"github.com/elliotxx/osp/pkg/repo"
+	"github.com/elliotxx/osp/pkg/util/diff"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +24,10 @@ This command will create or update an issue that summarizes all issues suitable
 organized by difficulty level and category. The content is designed to help new contributors find issues
 that match their interests and skill levels.
 
+The onboarding content can be rendered with a custom Go template via --template
+or the "onboard_template" entry of the repository in the config file. The template
+is read from local disk, or from the repository if no such local file exists.
+
 Examples:
   # Update onboarding content with default settings
   osp onboard
@@ -38,6 +44,9 @@ Examples:
   # Preview changes without updating any issues
   osp onboard --dry-run
 
+  # Show changes and exit with a non-zero status if there are any (e.g., in CI)
+  osp onboard --diff
+
   # Update automatically without confirmation
   osp onboard --yes
 
@@ -45,7 +54,13 @@ Examples:
   osp onboard --target-label="getting-started"
 
   # Specify a custom title for the target issue
-  osp onboard --target-title="Onboarding: Getting Started with Contributing"`,
+  osp onboard --target-title="Onboarding: Getting Started with Contributing"
+
+  # Render onboarding content with a custom template
+  osp onboard --template=.github/osp/onboard.gotmpl
+
+  # Mark issues whose assignee has been inactive for 14 days as stale claims
+  osp onboard --stale-claim-days=14`,
 	RunE: runOnboardUpdate,
 }
 
@@ -85,6 +100,10 @@ func runOnboardUpdate(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	showDiff, err := cmd.Flags().GetBool("diff")
+	if err != nil {
+		return err
+	}
 	dryRun, err := cmd.Flags().GetBool("dry-run")
 	if err != nil {
 		return err
@@ -101,6 +120,17 @@ func runOnboardUpdate(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	tmplPath, err := cmd.Flags().GetString("template")
+	if err != nil {
+		return err
+	}
+	staleClaimDays, err := cmd.Flags().GetInt("stale-claim-days")
+	if err != nil {
+		return err
+	}
+	if tmplPath == "" {
+		tmplPath = cfg.Repo(repoName).OnboardTemplate
+	}
 	log.Debug("Onboard labels: [%s]", strings.Join(onboardLabels, ", "))
 	log.Debug("Difficulty labels: [%s]", strings.Join(difficultyLabels, ", "))
 	log.Debug("Category labels: [%s]", strings.Join(categoryLabels, ", "))
@@ -121,8 +151,13 @@ func runOnboardUpdate(cmd *cobra.Command, _ []string) error {
 		// Target issue configuration
 		TargetLabel: targetLabel,
 		TargetTitle: targetTitle,
+		Template:    tmplPath,
+
+		// Claim configuration
+		StaleClaimDays: staleClaimDays,
 
 		// Command behavior
+		Diff:        showDiff,
 		DryRun:      dryRun,
 		AutoConfirm: autoConfirm,
 	}
@@ -135,6 +170,9 @@ func runOnboardUpdate(cmd *cobra.Command, _ []string) error {
 
 	// Update onboarding issue
 	err = onboardManager.Update(cmd.Context(), repoName, opts)
+	if errors.Is(err, diff.ErrChanges) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update onboarding issue: %w", err)
 	}
@@ -142,8 +180,108 @@ func runOnboardUpdate(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+var onboardClaimsCmd = &cobra.Command{
+	Use:   "claims",
+	Short: "Remind and release stale claims on onboarding issues",
+	Long: `Find the open onboarding issues whose assignee has made no comment, linked pull request
+or commit for a number of days. An open pull request of the assignee linked to the issue counts
+as activity until its last update.
+
+The assignee of a stale claim is first reminded with a polite comment. If there is still no
+activity after the grace period, the assignee is unassigned and the release label is added back,
+so that other contributors can pick the issue up. Issues shared by several assignees are skipped.
+
+Examples:
+  # Remind and release stale claims with default settings
+  osp onboard claims
+
+  # Remind after 14 days without activity, and release 3 days later
+  osp onboard claims --stale-claim-days=14 --grace-days=3
+
+  # Preview the stale claims without commenting or unassigning anyone
+  osp onboard claims --dry-run`,
+	RunE: runOnboardClaims,
+}
+
+func runOnboardClaims(cmd *cobra.Command, _ []string) error {
+	// Check authentication
+	if err := auth.CheckAuth(); err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Get repository name
+	repoManager, err := repo.NewManager(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create repository manager: %w", err)
+	}
+	repoName := repoManager.Current()
+	if repoName == "" {
+		return fmt.Errorf("no repository selected, use 'osp repo switch' to select a repository first")
+	}
+	log.Debug("Checking claims of onboarding issues for %s", repoName)
+
+	// Get flags
+	onboardLabels, err := cmd.Flags().GetStringSlice("onboard-labels")
+	if err != nil {
+		return err
+	}
+	staleClaimDays, err := cmd.Flags().GetInt("stale-claim-days")
+	if err != nil {
+		return err
+	}
+	graceDays, err := cmd.Flags().GetInt("grace-days")
+	if err != nil {
+		return err
+	}
+	releaseLabel, err := cmd.Flags().GetString("release-label")
+	if err != nil {
+		return err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	autoConfirm, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+
+	// Create GitHub client
+	client, err := api.DefaultRESTClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	// Create options
+	opts := onboard.DefaultOptions()
+	opts.OnboardLabels = onboardLabels
+	opts.StaleClaimDays = staleClaimDays
+	opts.ClaimGraceDays = graceDays
+	opts.ReleaseLabel = releaseLabel
+	opts.DryRun = dryRun
+	opts.AutoConfirm = autoConfirm
+
+	// Create onboard manager
+	onboardManager, err := onboard.NewManager(client)
+	if err != nil {
+		return fmt.Errorf("failed to create onboarding manager: %w", err)
+	}
+
+	if err := onboardManager.UpdateClaims(cmd.Context(), repoName, opts); err != nil {
+		return fmt.Errorf("failed to update claims: %w", err)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(onboardCmd)
+	onboardCmd.AddCommand(onboardClaimsCmd)
 
 	// Add flags
 	onboardCmd.Flags().StringSliceP("onboard-labels", "o", onboard.DefaultOptions().OnboardLabels, "Labels used to find issues suitable for community contribution (e.g., 'good first issue', 'help wanted')")
@@ -151,6 +289,16 @@ func init() {
 	onboardCmd.Flags().StringSliceP("category-labels", "c", onboard.DefaultOptions().CategoryLabels, "Labels used to classify issues by type within each difficulty level (e.g., 'bug', 'feature')")
 	onboardCmd.Flags().StringP("target-label", "t", onboard.DefaultOptions().TargetLabel, "Label used to locate the issue where onboarding content will be updated")
 	onboardCmd.Flags().StringP("target-title", "T", onboard.DefaultOptions().TargetTitle, "Title of the target issue where onboarding content will be updated")
+	onboardCmd.Flags().String("template", "", "Path of a custom onboarding template, on local disk or inside the repository (e.g., '.github/osp/onboard.gotmpl')")
+	onboardCmd.Flags().Int("stale-claim-days", onboard.DefaultOptions().StaleClaimDays, "Days without activity of the assignee after which an assigned open issue is marked as a stale claim, 0 to disable")
+	onboardCmd.Flags().Bool("diff", false, "Show the changes without modifying any issues, and exit with a non-zero status if there are any")
 	onboardCmd.Flags().BoolP("dry-run", "n", false, "Preview the changes without modifying any issues")
 	onboardCmd.Flags().BoolP("yes", "y", false, "Automatically apply changes without confirmation")
+
+	onboardClaimsCmd.Flags().StringSliceP("onboard-labels", "o", onboard.DefaultOptions().OnboardLabels, "Labels used to find issues suitable for community contribution (e.g., 'good first issue', 'help wanted')")
+	onboardClaimsCmd.Flags().Int("stale-claim-days", onboard.DefaultOptions().StaleClaimDays, "Days without activity of the assignee after which they are reminded")
+	onboardClaimsCmd.Flags().Int("grace-days", onboard.DefaultOptions().ClaimGraceDays, "Days after the reminder without activity after which the assignee is unassigned")
+	onboardClaimsCmd.Flags().String("release-label", onboard.DefaultOptions().ReleaseLabel, "Label added back to an issue when its assignee is unassigned, empty to add none")
+	onboardClaimsCmd.Flags().BoolP("dry-run", "n", false, "Preview the stale claims without commenting or unassigning anyone")
+	onboardClaimsCmd.Flags().BoolP("yes", "y", false, "Automatically remind and unassign without confirmation")
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/elliotxx/osp/pkg/log"
 	"github.com/elliotxx/osp/pkg/planning"
 	"github.com/elliotxx/osp/pkg/repo"
+	"github.com/elliotxx/osp/pkg/util/diff"
 	"github.com/spf13/cobra"
 )
 
@@ -18,14 +20,23 @@ var (
 	targetTitle   string
 	categories    []string
 	priorities    []string
+	maxHighPrio   int
 	excludePR     bool
+	planTemplate  string
+	showDiff      bool
 	dryRun        bool
 	autoConfirm   bool
+	projectNumber int
+	projectField  string
+	closedPlan    bool
+	carryOver     bool
+	planRepos     []string
+	planMilestone string
 )
 
 func newPlanCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "plan [milestone-number]",
+		Use:   "plan [milestone-number | field-value]",
 		Short: "Generate and update community planning",
 		Long: `Generate and maintain planning content to track milestone progress.
 
@@ -35,6 +46,27 @@ progress and highlight high-priority tasks.
 
 If no milestone number is provided, it will scan all open milestones.
 
+Repositories tracking releases in a GitHub Project instead of milestones can use
+--project with the project number. Its items are grouped by the single-select or
+iteration field given by --field, and each value of the field gets its own
+planning issue, filled the same way as for a milestone. In this mode the optional
+argument is the field value to update instead of a milestone number.
+
+Once a milestone is closed, --closed writes a final retrospective to its planning
+issue, listing what shipped, what was carried over, the contributors and the slip
+versus the due date, and then closes the planning issue. A milestone whose
+planning issue is closed already is skipped. Without a milestone number, it
+handles every closed milestone whose planning issue is still open.
+With --carry-over, the issues left open are moved to the next open milestone.
+
+Products spanning several repositories can be planned in a single issue with
+--repos and --milestone-title. The milestones with this title in each of the
+repositories are merged: issues are grouped by repository and category, and the
+statistics are combined. The planning issue is written to the current repository,
+which serves as the meta repository of the product. Its title is prefixed with
+"[multi-repo] ", so that it is distinct from the planning issue of the milestone
+with the same title in the meta repository itself.
+
 Available fields in title template:
   .Title       - Milestone title (e.g., "v1.0.0")
   .Description - Milestone description
@@ -43,6 +75,10 @@ Available fields in title template:
   .DueOn       - Milestone due date (e.g., "2025-12-31T23:59:59Z")
   .HTMLURL     - Milestone URL on GitHub
 
+The planning content can be rendered with a custom Go template via --template
+or the "plan_template" entry of the repository in the config file. The template
+is read from local disk, or from the repository if no such local file exists.
+
 Examples:
   # Update planning content for all open milestones
   osp plan
@@ -59,6 +95,9 @@ Examples:
   # Preview changes without updating any issues
   osp plan --dry-run
 
+  # Show changes and exit with a non-zero status if there are any (e.g., in CI)
+  osp plan --diff
+
   # Update automatically without confirmation
   osp plan --yes
 
@@ -71,8 +110,29 @@ Examples:
   # Use milestone fields in title template
   osp plan --target-title="Planning for {{ .Title }} (Due: {{ .DueOn.Format \"2006-01-02\" }})"
 
+  # Highlight assignees holding more than 2 open high-priority issues
+  osp plan --max-high-priority 2
+
   # Exclude pull requests from planning content
-  osp plan --exclude-pr`,
+  osp plan --exclude-pr
+
+  # Render planning content with a custom template
+  osp plan --template=.github/osp/planning.gotmpl
+
+  # Update planning content for each iteration of project #3
+  osp plan --project 3 --field Iteration
+
+  # Update planning content for a single iteration of project #3
+  osp plan --project 3 --field Iteration "Iteration 5"
+
+  # Write the retrospective of closed milestone #1 and close its planning issue
+  osp plan --closed 1
+
+  # Also move the issues left open to the next milestone
+  osp plan --closed 1 --carry-over
+
+  # Plan milestone v1.2 across repositories in the current repository
+  osp plan --repos org/a,org/b --milestone-title v1.2`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runPlanUpdate,
 	}
@@ -82,9 +142,20 @@ Examples:
 	cmd.Flags().StringVarP(&targetTitle, "target-title", "T", planning.DefaultOptions().TargetTitle, "Title template of the target issue where planning content will be updated. Available fields: .Title, .Description, .Number, .State, .DueOn, .HTMLURL of the milestone")
 	cmd.Flags().StringSliceVarP(&categories, "category-labels", "c", planning.DefaultOptions().Categories, "Labels used to classify issues by type (e.g., 'bug', 'feature')")
 	cmd.Flags().StringSliceVarP(&priorities, "priority-labels", "p", planning.DefaultOptions().Priorities, "Labels used to indicate issue priority, ordered from high to low (e.g., 'priority/high', 'priority/medium')")
+	cmd.Flags().IntVar(&maxHighPrio, "max-high-priority", planning.DefaultOptions().MaxHighPriority, "Maximum number of open high-priority issues per assignee before they are highlighted as overloaded")
 	cmd.Flags().BoolVarP(&excludePR, "exclude-pr", "e", planning.DefaultOptions().ExcludePR, "Exclude pull requests from planning content")
+	cmd.Flags().StringVar(&planTemplate, "template", "", "Path of a custom planning template, on local disk or inside the repository (e.g., '.github/osp/planning.gotmpl')")
+	cmd.Flags().BoolVar(&showDiff, "diff", planning.DefaultOptions().Diff, "Show the changes without modifying any issues, and exit with a non-zero status if there are any")
 	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", planning.DefaultOptions().DryRun, "Preview the changes without modifying any issues")
 	cmd.Flags().BoolVarP(&autoConfirm, "yes", "y", planning.DefaultOptions().AutoConfirm, "Automatically apply changes without confirmation")
+	cmd.Flags().IntVar(&projectNumber, "project", 0, "Number of the GitHub Project to plan from instead of milestones")
+	cmd.Flags().StringVar(&projectField, "field", "Iteration", "Single-select or iteration field of the project used to group items, with --project")
+	cmd.Flags().BoolVar(&closedPlan, "closed", false, "Write the final retrospective of closed milestones and close their planning issues")
+	cmd.Flags().BoolVar(&carryOver, "carry-over", planning.DefaultOptions().CarryOver, "Move the issues left open in closed milestones to the next open milestone, with --closed")
+	cmd.Flags().StringSliceVar(&planRepos, "repos", nil, "Repositories whose milestones titled --milestone-title are planned together in the current repository (e.g., 'org/a,org/b')")
+	cmd.Flags().StringVar(&planMilestone, "milestone-title", "", "Title of the milestones planned together, with --repos")
+	cmd.MarkFlagsRequiredTogether("repos", "milestone-title")
+	cmd.MarkFlagsMutuallyExclusive("closed", "project", "repos")
 
 	return cmd
 }
@@ -124,18 +195,52 @@ func runPlanUpdate(cmd *cobra.Command, args []string) error {
 	}
 	owner, repoName := parts[0], parts[1]
 
+	// Get GitHub GraphQL client
+	gqlClient, err := api.DefaultGraphQLClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub GraphQL client: %w", err)
+	}
+
 	// Create plan manager
-	manager := planning.NewManager(client)
+	manager := planning.NewManager(client, gqlClient)
+
+	// Fall back to the template configured for the repository
+	tmplPath := planTemplate
+	if tmplPath == "" {
+		tmplPath = cfg.Repo(currentRepo).PlanTemplate
+	}
 
 	// Create options
 	opts := planning.Options{
-		PlanningLabel: planningLabel,
-		TargetTitle:   targetTitle,
-		Categories:    categories,
-		Priorities:    priorities,
-		ExcludePR:     excludePR,
-		DryRun:        dryRun,
-		AutoConfirm:   autoConfirm,
+		PlanningLabel:   planningLabel,
+		TargetTitle:     targetTitle,
+		Categories:      categories,
+		Priorities:      priorities,
+		MaxHighPriority: maxHighPrio,
+		ExcludePR:       excludePR,
+		Template:        tmplPath,
+		Diff:            showDiff,
+		DryRun:          dryRun,
+		AutoConfirm:     autoConfirm,
+		CarryOver:       carryOver,
+	}
+
+	// Plan from the project if one is provided
+	if projectNumber > 0 {
+		return runProjectPlanUpdate(cmd, manager, owner, repoName, args, opts)
+	}
+
+	// Plan the milestones across repositories if requested
+	if len(planRepos) > 0 {
+		if len(args) > 0 {
+			return fmt.Errorf("milestone number cannot be used with --repos, use --milestone-title instead")
+		}
+		return manager.UpdateRepos(cmd.Context(), owner, repoName, planRepos, planMilestone, opts)
+	}
+
+	// Write the retrospective of closed milestones if requested
+	if closedPlan {
+		return runPlanClose(cmd, manager, owner, repoName, args, opts)
 	}
 
 	// If milestone number is provided, update that specific milestone
@@ -161,12 +266,116 @@ func runPlanUpdate(cmd *cobra.Command, args []string) error {
 	}
 
 	log.Info("Found %d open milestones", len(milestones))
+	outdated := false
 	for _, m := range milestones {
 		if err := manager.Update(cmd.Context(), owner, repoName, m.Number, opts); err != nil {
+			if errors.Is(err, diff.ErrChanges) {
+				log.Warn("%v", err)
+				outdated = true
+				continue
+			}
 			log.Error("Failed to update planning for milestone %d: %v", m.Number, err)
 			continue
 		}
 	}
 
+	// Report changes in diff mode
+	if outdated {
+		return diff.ErrChanges
+	}
+
+	return nil
+}
+
+// runPlanClose writes the retrospective of the closed milestone given as
+// argument, or of every closed milestone whose planning issue is still open
+func runPlanClose(cmd *cobra.Command, manager *planning.Manager, owner, repoName string, args []string, opts planning.Options) error {
+	if len(args) > 0 {
+		var milestoneNumber int
+		_, err := fmt.Sscanf(args[0], "%d", &milestoneNumber)
+		if err != nil {
+			return fmt.Errorf("invalid milestone number: %w", err)
+		}
+
+		return manager.Close(cmd.Context(), owner, repoName, milestoneNumber, opts)
+	}
+
+	milestones, err := manager.ListPendingRetrospectives(cmd.Context(), owner, repoName, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list closed milestones: %w", err)
+	}
+
+	if len(milestones) == 0 {
+		log.Info("No closed milestones with an open planning issue found")
+		return nil
+	}
+
+	log.Info("Found %d closed milestones with an open planning issue", len(milestones))
+	outdated := false
+	for _, m := range milestones {
+		if err := manager.Close(cmd.Context(), owner, repoName, m.Number, opts); err != nil {
+			if errors.Is(err, diff.ErrChanges) {
+				log.Warn("%v", err)
+				outdated = true
+				continue
+			}
+			log.Error("Failed to close planning for milestone %d: %v", m.Number, err)
+			continue
+		}
+	}
+
+	// Report changes in diff mode
+	if outdated {
+		return diff.ErrChanges
+	}
+
+	return nil
+}
+
+// runProjectPlanUpdate updates the planning of each value of the project field,
+// or of the value given as argument
+func runProjectPlanUpdate(cmd *cobra.Command, manager *planning.Manager, owner, repoName string, args []string, opts planning.Options) error {
+	project, groups, err := manager.ListProjectGroups(cmd.Context(), owner, repoName, projectNumber, projectField, opts.ExcludePR)
+	if err != nil {
+		return fmt.Errorf("failed to list project items: %w", err)
+	}
+
+	if len(args) > 0 {
+		var selected []planning.ProjectGroup
+		for _, group := range groups {
+			if group.Value == args[0] {
+				selected = append(selected, group)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("no items of %s/%s with %s '%s' found in project #%d", owner, repoName, projectField, args[0], projectNumber)
+		}
+		groups = selected
+	}
+
+	if len(groups) == 0 {
+		log.Info("No items with field '%s' found in project #%d", projectField, projectNumber)
+		return nil
+	}
+
+	log.Info("Found %d values of field '%s' in project '%s'", len(groups), projectField, project.Title)
+	outdated := false
+	for _, group := range groups {
+		if err := manager.UpdateProject(cmd.Context(), owner, repoName, project, group, opts); err != nil {
+			if errors.Is(err, diff.ErrChanges) {
+				log.Warn("%v", err)
+				outdated = true
+				continue
+			}
+			log.Error("Failed to update planning for %s '%s': %v", projectField, group.Value, err)
+			continue
+		}
+	}
+
+	// Report changes in diff mode
+	if outdated {
+		return diff.ErrChanges
+	}
+
 	return nil
 }
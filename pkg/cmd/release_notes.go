@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/auth"
+	"github.com/elliotxx/osp/pkg/config"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/release"
+	"github.com/elliotxx/osp/pkg/repo"
+	"github.com/spf13/cobra"
+)
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes",
+	Short: "Generate release notes",
+	Long: `Generate release notes from merged pull requests and closed issues.
+
+The release is either a range of tags (--from and --to) or a milestone
+(--milestone). Without --from, the tag preceding --to in the local clone is used,
+and without --to, the notes cover the changes not released yet.
+
+Pull requests and issues are grouped by the same category labels as 'osp plan'.
+The notes also thank first-time contributors and list participation metrics.
+They are printed to stdout as Markdown, or written into a draft GitHub release
+of the --to tag (or the milestone title) with --draft.
+
+The notes can be rendered with a custom Go template via --template or the
+"release_notes_template" entry of the repository in the config file.
+
+Examples:
+  # Generate the notes of a release
+  osp release-notes --from v0.3.0 --to v0.4.0
+
+  # Generate the notes of the changes since the latest tag
+  osp release-notes
+
+  # Generate the notes of a milestone
+  osp release-notes --milestone 5
+
+  # Write the notes into a draft release
+  osp release-notes --to v0.4.0 --draft`,
+	RunE: runReleaseNotes,
+}
+
+func runReleaseNotes(cmd *cobra.Command, _ []string) error {
+	// Check authentication
+	if err := auth.CheckAuth(); err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Get repository name
+	repoManager, err := repo.NewManager(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create repository manager: %w", err)
+	}
+	repoName := repoManager.Current()
+	if repoName == "" {
+		return fmt.Errorf("no repository selected, use 'osp repo switch' to select a repository first")
+	}
+	owner, name, ok := strings.Cut(repoName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository format: %s", repoName)
+	}
+
+	// Get flags
+	opts := release.DefaultOptions()
+	if opts.From, err = cmd.Flags().GetString("from"); err != nil {
+		return err
+	}
+	if opts.To, err = cmd.Flags().GetString("to"); err != nil {
+		return err
+	}
+	if opts.Milestone, err = cmd.Flags().GetInt("milestone"); err != nil {
+		return err
+	}
+	if opts.Milestone > 0 && (opts.From != "" || opts.To != "") {
+		return fmt.Errorf("--milestone cannot be used with --from or --to")
+	}
+	if opts.Categories, err = cmd.Flags().GetStringSlice("category-labels"); err != nil {
+		return err
+	}
+	if opts.Template, err = cmd.Flags().GetString("template"); err != nil {
+		return err
+	}
+	if opts.Template == "" {
+		opts.Template = cfg.Repo(repoName).ReleaseNotesTemplate
+	}
+	draft, err := cmd.Flags().GetBool("draft")
+	if err != nil {
+		return err
+	}
+	if draft && opts.Milestone == 0 && opts.To == "" {
+		return fmt.Errorf("--draft requires the tag of the release, specify it with --to")
+	}
+	if opts.DryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+		return err
+	}
+	if opts.AutoConfirm, err = cmd.Flags().GetBool("yes"); err != nil {
+		return err
+	}
+
+	// Create GitHub client
+	client, err := api.DefaultRESTClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	// Generate release notes
+	log.Debug("Generating release notes for %s", repoName)
+	manager := release.NewManager(client)
+	data, content, err := manager.Generate(cmd.Context(), owner, name, opts)
+	if err != nil {
+		return err
+	}
+
+	if !draft {
+		fmt.Fprint(cmd.OutOrStdout(), content)
+		return nil
+	}
+	if err := manager.SaveDraft(owner, name, data.Title, content, opts); err != nil {
+		return fmt.Errorf("failed to save draft release: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(releaseNotesCmd)
+
+	// Add flags
+	releaseNotesCmd.Flags().String("from", "", "Tag the release starts after (default: the tag preceding --to in the local clone)")
+	releaseNotesCmd.Flags().String("to", "", "Tag of the release (default: the changes not released yet)")
+	releaseNotesCmd.Flags().Int("milestone", 0, "Generate the notes of a milestone instead of a range of tags")
+	releaseNotesCmd.Flags().StringSlice("category-labels", release.DefaultOptions().Categories, "Labels used to group pull requests and issues by type")
+	releaseNotesCmd.Flags().String("template", "", "Path of a custom release notes template, on local disk or inside the repository (e.g., '.github/osp/release-notes.gotmpl')")
+	releaseNotesCmd.Flags().Bool("draft", false, "Write the notes into a draft release of the tag (or the milestone title) instead of printing them")
+	releaseNotesCmd.Flags().BoolP("dry-run", "n", release.DefaultOptions().DryRun, "Preview the draft release without modifying it")
+	releaseNotesCmd.Flags().BoolP("yes", "y", release.DefaultOptions().AutoConfirm, "Automatically apply changes without confirmation")
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/auth"
+	"github.com/elliotxx/osp/pkg/config"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/repo"
+	"github.com/elliotxx/osp/pkg/roadmap"
+	"github.com/elliotxx/osp/pkg/util/diff"
+	"github.com/spf13/cobra"
+)
+
+var roadmapCmd = &cobra.Command{
+	Use:   "roadmap",
+	Short: "Generate and update the roadmap across all milestones",
+	Long: `Generate and maintain a single roadmap issue covering all open milestones.
+
+While 'osp plan' writes one planning issue per milestone, this command creates or
+updates one issue listing every open milestone in due date order, each with its
+progress bar, due date, overdue status and top-priority open issues. The roadmap
+issue is pinned to the repository unless --pin=false is given.
+
+The roadmap content can be rendered with a custom Go template via --template
+or the "roadmap_template" entry of the repository in the config file. The template
+is read from local disk, or from the repository if no such local file exists.
+
+Examples:
+  # Update the roadmap issue
+  osp roadmap
+
+  # Use custom priority labels and list more top-priority issues
+  osp roadmap --priority-labels="P0,P1,P2" --top-items 10
+
+  # Preview changes without updating any issues
+  osp roadmap --dry-run
+
+  # Show changes and exit with a non-zero status if there are any (e.g., in CI)
+  osp roadmap --diff
+
+  # Update automatically without confirmation
+  osp roadmap --yes`,
+	RunE: runRoadmapUpdate,
+}
+
+func runRoadmapUpdate(cmd *cobra.Command, _ []string) error {
+	// Check authentication
+	if err := auth.CheckAuth(); err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Get repository name
+	repoManager, err := repo.NewManager(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create repository manager: %w", err)
+	}
+	repoName := repoManager.Current()
+	if repoName == "" {
+		return fmt.Errorf("no repository selected, use 'osp repo switch' to select a repository first")
+	}
+	owner, name, ok := strings.Cut(repoName, "/")
+	if !ok {
+		return fmt.Errorf("invalid repository format: %s", repoName)
+	}
+	log.Debug("Generating roadmap for %s", repoName)
+
+	// Get flags
+	opts := roadmap.DefaultOptions()
+	if opts.RoadmapLabel, err = cmd.Flags().GetString("target-label"); err != nil {
+		return err
+	}
+	if opts.TargetTitle, err = cmd.Flags().GetString("target-title"); err != nil {
+		return err
+	}
+	if opts.Priorities, err = cmd.Flags().GetStringSlice("priority-labels"); err != nil {
+		return err
+	}
+	if opts.TopItems, err = cmd.Flags().GetInt("top-items"); err != nil {
+		return err
+	}
+	if opts.ExcludePR, err = cmd.Flags().GetBool("exclude-pr"); err != nil {
+		return err
+	}
+	if opts.Pin, err = cmd.Flags().GetBool("pin"); err != nil {
+		return err
+	}
+	if opts.Template, err = cmd.Flags().GetString("template"); err != nil {
+		return err
+	}
+	if opts.Template == "" {
+		opts.Template = cfg.Repo(repoName).RoadmapTemplate
+	}
+	if opts.Diff, err = cmd.Flags().GetBool("diff"); err != nil {
+		return err
+	}
+	if opts.DryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+		return err
+	}
+	if opts.AutoConfirm, err = cmd.Flags().GetBool("yes"); err != nil {
+		return err
+	}
+
+	// Create GitHub clients
+	client, err := api.DefaultRESTClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	gqlClient, err := api.DefaultGraphQLClient()
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub GraphQL client: %w", err)
+	}
+
+	// Update roadmap
+	err = roadmap.NewManager(client, gqlClient).Update(cmd.Context(), owner, name, opts)
+	if errors.Is(err, diff.ErrChanges) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update roadmap: %w", err)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(roadmapCmd)
+
+	// Add flags
+	roadmapCmd.Flags().StringP("target-label", "t", roadmap.DefaultOptions().RoadmapLabel, "Label used to locate the issue where the roadmap will be updated")
+	roadmapCmd.Flags().StringP("target-title", "T", roadmap.DefaultOptions().TargetTitle, "Title of the roadmap issue")
+	roadmapCmd.Flags().StringSliceP("priority-labels", "p", roadmap.DefaultOptions().Priorities, "Labels used to indicate issue priority, ordered from high to low")
+	roadmapCmd.Flags().Int("top-items", roadmap.DefaultOptions().TopItems, "Maximum number of top-priority open issues listed for each milestone")
+	roadmapCmd.Flags().BoolP("exclude-pr", "e", roadmap.DefaultOptions().ExcludePR, "Exclude pull requests from the roadmap")
+	roadmapCmd.Flags().Bool("pin", roadmap.DefaultOptions().Pin, "Pin the roadmap issue to the repository")
+	roadmapCmd.Flags().String("template", "", "Path of a custom roadmap template, on local disk or inside the repository (e.g., '.github/osp/roadmap.gotmpl')")
+	roadmapCmd.Flags().Bool("diff", roadmap.DefaultOptions().Diff, "Show the changes without modifying any issues, and exit with a non-zero status if there are any")
+	roadmapCmd.Flags().BoolP("dry-run", "n", roadmap.DefaultOptions().DryRun, "Preview the changes without modifying any issues")
+	roadmapCmd.Flags().BoolP("yes", "y", roadmap.DefaultOptions().AutoConfirm, "Automatically apply changes without confirmation")
+}
@@ -1,24 +1,38 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/elliotxx/osp/pkg/config"
+	"github.com/elliotxx/osp/pkg/log"
 	"github.com/elliotxx/osp/pkg/stats"
+	"github.com/elliotxx/osp/pkg/util/output"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 const (
-	outputFormatJSON = "json"
+	outputFormatSVG = "svg"
+	outputFormatPNG = "png"
+
+	// defaultTerminalWidth is used when the output is not a terminal
+	defaultTerminalWidth = 80
 )
 
 var statsCmd = &cobra.Command{
 	Use:   "stats [repository]",
 	Short: "Show repository statistics",
-	Long:  "Show repository statistics such as stars, forks, and open issues",
+	Long: `Show repository statistics such as stars, forks, and open issues, together with
+health metrics: watchers, open pull requests, contributors, the latest release and
+its age, and the commits and merged pull requests of the last 30 days.
+
+Each run records a daily snapshot of the statistics in the local data directory,
+which can be listed with 'osp stats history' or queried with --offline.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get repository name from args or current
 		var repoName string
@@ -36,32 +50,244 @@ var statsCmd = &cobra.Command{
 		format, _ := cmd.Flags().GetString("format")
 
 		// Create stats manager
-		manager, err := stats.NewManager()
+		manager, err := newStatsManager(cmd)
 		if err != nil {
 			return err
 		}
 
 		// Get stats
-		stats, err := manager.Get(context.Background(), repoName)
+		repoStats, err := manager.Get(context.Background(), repoName)
 		if err != nil {
 			return err
 		}
 
 		// Output stats
-		switch strings.ToLower(format) {
-		case outputFormatJSON:
-			data, err := json.MarshalIndent(stats, "", "  ")
+		if !output.IsText(format) {
+			return output.Write(os.Stdout, format, repoStats)
+		}
+		fmt.Printf("Repository: %s\n", repoName)
+		fmt.Printf("Stars: %d\n", repoStats.Stars)
+		fmt.Printf("Forks: %d\n", repoStats.Forks)
+		fmt.Printf("Watchers: %d\n", repoStats.Watchers)
+		fmt.Printf("Open Issues: %d\n", repoStats.OpenIssues)
+		fmt.Printf("Open Pull Requests: %d\n", repoStats.OpenPRs)
+		fmt.Printf("Merged Pull Requests: %d (%d in the last %d days)\n", repoStats.MergedPRs, repoStats.RecentMergedPRs, stats.ActivityDays)
+		fmt.Printf("Commits: %d in the last %d days\n", repoStats.RecentCommits, stats.ActivityDays)
+		fmt.Printf("Contributors: %d\n", repoStats.Contributors)
+		if repoStats.LatestReleaseAt != nil {
+			fmt.Printf("Latest Release: %s (%d days ago)\n", repoStats.LatestRelease, int(repoStats.ReleaseAge(time.Now()).Hours()/24))
+		} else {
+			fmt.Println("Latest Release: none")
+		}
+		fmt.Printf("Last Updated: %s\n", repoStats.LastUpdated)
+
+		return nil
+	},
+}
+
+var statsHistoryCmd = &cobra.Command{
+	Use:   "history [repository]",
+	Short: "Show recorded statistics snapshots",
+	Long: `Show the daily statistics snapshots recorded locally by previous 'osp stats' runs.
+
+This command works offline and never calls the GitHub API.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get repository name from args or current
+		var repoName string
+		if len(args) > 0 {
+			repoName = args[0]
+		} else {
+			state, err := config.LoadState()
 			if err != nil {
-				return err
+				return fmt.Errorf("failed to load state: %w", err)
 			}
-			fmt.Println(string(data))
+			repoName = state.Current
+		}
 
-		default:
-			fmt.Printf("Repository: %s\n", repoName)
-			fmt.Printf("Stars: %d\n", stats.Stars)
-			fmt.Printf("Forks: %d\n", stats.Forks)
-			fmt.Printf("Open Issues: %d\n", stats.OpenIssues)
-			fmt.Printf("Last Updated: %s\n", stats.LastUpdated)
+		// Get flags
+		days, _ := cmd.Flags().GetInt("days")
+		format, _ := cmd.Flags().GetString("format")
+
+		// Create stats manager
+		manager, err := stats.NewManager(stats.DefaultOptions())
+		if err != nil {
+			return err
+		}
+
+		// Get snapshots
+		snapshots, err := manager.GetHistory(repoName, days)
+		if err != nil {
+			return err
+		}
+
+		// Output snapshots
+		if !output.IsText(format) {
+			return output.Write(os.Stdout, format, snapshots)
+		}
+		if len(snapshots) == 0 {
+			fmt.Printf("No statistics recorded for %s yet, run 'osp stats' to record one\n", repoName)
+			return nil
+		}
+		fmt.Printf("Statistics history for %s:\n\n", repoName)
+		for _, s := range snapshots {
+			fmt.Printf("%s: %d stars, %d forks, %d open issues\n", s.Date, s.Stars, s.Forks, s.OpenIssues)
+		}
+
+		return nil
+	},
+}
+
+var statsResponseCmd = &cobra.Command{
+	Use:   "response [repository]",
+	Short: "Show issue and pull request response times",
+	Long: `Show the time to first response and the time to close of the issues and pull
+requests opened in the last days, with medians, 90th percentiles and monthly trends.
+
+A response is a comment, review comment or review by anyone but the author. Issues, pull
+requests and comments from bots are ignored, and --exclude-maintainers also ignores
+issues and pull requests opened by maintainers, so that the response times measure how
+fast the community is answered. Responses from maintainers are always counted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get repository name from args or current
+		var repoName string
+		if len(args) > 0 {
+			repoName = args[0]
+		} else {
+			state, err := config.LoadState()
+			if err != nil {
+				return fmt.Errorf("failed to load state: %w", err)
+			}
+			repoName = state.Current
+		}
+
+		// Get flags
+		opts := stats.DefaultResponseOptions()
+		opts.Days, _ = cmd.Flags().GetInt("days")
+		opts.ExcludeMaintainers, _ = cmd.Flags().GetBool("exclude-maintainers")
+		format, _ := cmd.Flags().GetString("format")
+
+		// Create stats manager
+		manager, err := stats.NewManager(stats.DefaultOptions())
+		if err != nil {
+			return err
+		}
+
+		// Get response times
+		report, err := manager.GetResponseTimes(context.Background(), repoName, opts)
+		if err != nil {
+			return err
+		}
+
+		// Output report
+		if !output.IsText(format) {
+			return output.Write(os.Stdout, format, report)
+		}
+		fmt.Printf("Response times for %s (%s to %s):\n\n", repoName, report.From.Format("2006-01-02"), report.To.Format("2006-01-02"))
+		fmt.Printf("%-14s %6s %10s %10s %10s %10s\n", "", "Count", "Resp p50", "Resp p90", "Close p50", "Close p90")
+		printResponseStats("Issues", report.Issues)
+		printResponseStats("Pull Requests", report.PullRequests)
+
+		if len(report.Months) > 0 {
+			fmt.Println()
+			fmt.Println("Monthly trend (median time to first response):")
+			for _, month := range report.Months {
+				fmt.Printf("  %s: issues %s (%d), pull requests %s (%d)\n", month.Month,
+					formatHours(month.Issues.FirstResponseMedian, month.Issues.Responded), month.Issues.Count,
+					formatHours(month.PullRequests.FirstResponseMedian, month.PullRequests.Responded), month.PullRequests.Count)
+			}
+		}
+
+		return nil
+	},
+}
+
+// printResponseStats prints a row of response times
+func printResponseStats(name string, s stats.ResponseStats) {
+	fmt.Printf("%-14s %6d %10s %10s %10s %10s\n", name, s.Count,
+		formatHours(s.FirstResponseMedian, s.Responded), formatHours(s.FirstResponseP90, s.Responded),
+		formatHours(s.CloseMedian, s.Closed), formatHours(s.CloseP90, s.Closed))
+}
+
+// formatHours formats a duration in hours compactly, or "-" if there is no sample
+func formatHours(hours float64, samples int) string {
+	switch {
+	case samples == 0:
+		return "-"
+	case hours < 1:
+		return fmt.Sprintf("%dm", int(hours*60))
+	case hours < 48:
+		return fmt.Sprintf("%.1fh", hours)
+	default:
+		return fmt.Sprintf("%.1fd", hours/24)
+	}
+}
+
+var statsContributorsCmd = &cobra.Command{
+	Use:   "contributors [repository]",
+	Short: "Show contributor analytics",
+	Long: `Show the contributors of the commits and merged pull requests of the last days,
+compared with the previous period of the same length.
+
+First-time contributors had never committed to the repository before, returning
+contributors had. The bus factor is the fewest contributors accounting for half of
+the contributions, and lost contributors were active in the previous period but
+not in this one. Bots are ignored. If the previous period has too many contributions
+to list them all, it is excluded from the comparison.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get repository name from args or current
+		var repoName string
+		if len(args) > 0 {
+			repoName = args[0]
+		} else {
+			state, err := config.LoadState()
+			if err != nil {
+				return fmt.Errorf("failed to load state: %w", err)
+			}
+			repoName = state.Current
+		}
+
+		// Get flags
+		opts := stats.DefaultContributorOptions()
+		opts.Days, _ = cmd.Flags().GetInt("days")
+		opts.Top, _ = cmd.Flags().GetInt("top")
+		format, _ := cmd.Flags().GetString("format")
+
+		// Create stats manager
+		manager, err := stats.NewManager(stats.DefaultOptions())
+		if err != nil {
+			return err
+		}
+
+		// Get contributors
+		report, err := manager.GetContributors(context.Background(), repoName, opts)
+		if err != nil {
+			return err
+		}
+
+		// Output report
+		if !output.IsText(format) {
+			return output.Write(os.Stdout, format, report)
+		}
+		fmt.Printf("Contributors of %s (%s to %s):\n\n", repoName, report.From.Format("2006-01-02"), report.To.Format("2006-01-02"))
+		for _, c := range report.Contributors {
+			mark := ""
+			if c.FirstTime {
+				mark = log.Colorize(log.ColorGreen, " (first time)")
+			}
+			fmt.Printf("  %-24s %4d commits %4d merged PRs%s\n", c.Login, c.Commits, c.MergedPRs, mark)
+		}
+		fmt.Println()
+		fmt.Printf("Contributors: %d (%d new, %d returning)\n", len(report.Contributors), report.New, report.Returning)
+		fmt.Printf("Bus Factor: %d\n", report.BusFactor)
+		fmt.Printf("Top %d Share: %.0f%%\n", report.Top, report.TopShare*100)
+		if report.PreviousExcluded {
+			fmt.Printf("\nThe previous %d days had too many contributions to compare with\n", opts.Days)
+		}
+		if len(report.Lost) > 0 {
+			fmt.Printf("\nLost contributors (active in the previous %d days):\n", opts.Days)
+			for _, c := range report.Lost {
+				fmt.Printf("  %-24s %4d commits %4d merged PRs\n", c.Login, c.Commits, c.MergedPRs)
+			}
 		}
 
 		return nil
@@ -77,7 +303,31 @@ var starCmd = &cobra.Command{
 var starHistoryCmd = &cobra.Command{
 	Use:   "history [owner/repo]",
 	Short: "Show star history",
-	Long:  `Show the history of stars for a repository over time.`,
+	Long: `Show the history of stars for a repository over time.
+
+The daily star counts are computed from the time each stargazer starred the
+repository. Stargazers are stored in the local data directory, so later runs only
+fetch new stargazers and --offline works without calling the GitHub API. For
+repositories with a huge number of stars, the stargazers are sampled and the
+counts between samples are estimated.
+
+The text output is a chart fitting the terminal width, with the stars gained in
+each day, week or month (--interval) drawn below the cumulative stars.
+
+The svg format renders a self-contained chart that can be committed and embedded
+in a README, with a line for each repository given by --compare. The png format
+renders the same chart as an image, for places where SVG is not displayed.`,
+	Example: `  # Show the star history of the last 30 days
+  osp star history
+
+  # Export the whole star history as an SVG chart
+  osp star history --days 0 --format svg --output stars.svg
+
+  # Export the whole star history as a PNG image
+  osp star history --days 0 --format png --output stars.png
+
+  # Compare the star history with other repositories
+  osp star history --days 0 --format svg --output stars.svg --compare owner/other`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get repository name from args or current
 		var repoName string
@@ -94,32 +344,62 @@ var starHistoryCmd = &cobra.Command{
 		// Get flags
 		days, _ := cmd.Flags().GetInt("days")
 		format, _ := cmd.Flags().GetString("format")
-
-		// Create stats manager
-		manager, err := stats.NewManager()
+		outputPath, _ := cmd.Flags().GetString("output")
+		compare, _ := cmd.Flags().GetStringSlice("compare")
+		intervalName, _ := cmd.Flags().GetString("interval")
+		interval, err := stats.ParseInterval(intervalName)
 		if err != nil {
 			return err
 		}
 
-		// Get star history
-		history, err := manager.GetStarHistory(context.Background(), repoName, days)
+		// Create stats manager
+		manager, err := newStatsManager(cmd)
 		if err != nil {
 			return err
 		}
 
+		// Get star history of the repository and the ones to compare with
+		var series []stats.StarSeries
+		for _, name := range append([]string{repoName}, compare...) {
+			history, err := manager.GetStarHistory(context.Background(), name, days)
+			if err != nil {
+				return fmt.Errorf("failed to get star history of %s: %w", name, err)
+			}
+			series = append(series, stats.StarSeries{
+				Repository: name,
+				History:    stats.GroupStarHistory(history, interval),
+			})
+		}
+
 		// Output history
-		switch strings.ToLower(format) {
-		case outputFormatJSON:
-			data, err := json.MarshalIndent(history, "", "  ")
+		switch {
+		case strings.EqualFold(format, outputFormatSVG):
+			return writeOutput(outputPath, []byte(stats.RenderStarHistorySVG(series, stats.SVGWidth, stats.SVGHeight)))
+
+		case strings.EqualFold(format, outputFormatPNG):
+			image, err := stats.RenderStarHistoryPNG(series, stats.SVGWidth, stats.SVGHeight)
 			if err != nil {
 				return err
 			}
-			fmt.Println(string(data))
+			return writeOutput(outputPath, image)
+
+		case !output.IsText(format):
+			var buf bytes.Buffer
+			var v interface{} = series
+			if len(series) == 1 {
+				v = series[0].History
+			}
+			if err := output.Write(&buf, format, v); err != nil {
+				return err
+			}
+			return writeOutput(outputPath, buf.Bytes())
 
 		default:
-			fmt.Printf("Star history for %s (last %d days):\n\n", repoName, days)
-			for _, h := range history {
-				fmt.Printf("%s: %d stars\n", h.Date.Format("2006-01-02"), h.Stars)
+			for i, s := range series {
+				if i > 0 {
+					fmt.Println()
+				}
+				printStarChart(s, days, interval)
 			}
 		}
 
@@ -127,16 +407,111 @@ var starHistoryCmd = &cobra.Command{
 	},
 }
 
+// printStarChart prints the star history of a repository as a terminal chart with a summary
+func printStarChart(series stats.StarSeries, days int, interval stats.Interval) {
+	if days > 0 {
+		fmt.Printf("Star history for %s (last %d days):\n\n", series.Repository, days)
+	} else {
+		fmt.Printf("Star history for %s (since creation):\n\n", series.Repository)
+	}
+	history := series.History
+	if len(history) == 0 {
+		fmt.Println("No star history")
+		return
+	}
+	for _, line := range stats.RenderStarChart(history, interval, terminalWidth()) {
+		fmt.Println(line)
+	}
+
+	// Summarize the stars gained over the period
+	gained, best := 0, history[0]
+	for _, h := range history {
+		gained += h.Delta
+		if h.Delta > best.Delta {
+			best = h
+		}
+	}
+	fmt.Println()
+	fmt.Printf("Stars: %d (%s over the period)\n", history[len(history)-1].Stars, formatDelta(gained))
+	fmt.Printf("Average: %.1f stars per %s\n", float64(gained)/float64(len(history)), interval)
+	if best.Delta > 0 {
+		fmt.Printf("Best %s: %s (%s)\n", interval, best.Date.Format("2006-01-02"), formatDelta(best.Delta))
+	}
+}
+
+// writeOutput writes data to the file at path, or to stdout if path is empty
+func writeOutput(path string, data []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	log.Success("Written to %s", path)
+	return nil
+}
+
+// terminalWidth returns the width of the terminal, or a default width if the
+// output is not a terminal
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+	return width
+}
+
+// formatDelta formats a change in count with its sign, colored by direction
+func formatDelta(delta int) string {
+	switch {
+	case delta > 0:
+		return log.Colorize(log.ColorGreen, fmt.Sprintf("+%d", delta))
+	case delta < 0:
+		return log.Colorize(log.ColorRed, fmt.Sprintf("%d", delta))
+	default:
+		return "+0"
+	}
+}
+
+// newStatsManager creates a stats manager from the local store flags of the command
+func newStatsManager(cmd *cobra.Command) (*stats.Manager, error) {
+	offline, _ := cmd.Flags().GetBool("offline")
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	return stats.NewManager(stats.Options{
+		Offline: offline,
+		NoCache: noCache,
+	})
+}
+
 func init() {
 	// Add stats commands
 	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsHistoryCmd)
+	statsCmd.AddCommand(statsResponseCmd)
+	statsCmd.AddCommand(statsContributorsCmd)
 
 	// Add star commands
 	rootCmd.AddCommand(starCmd)
 	starCmd.AddCommand(starHistoryCmd)
 
 	// Add flags
-	statsCmd.Flags().String("format", "text", "Output format (text, json)")
-	starHistoryCmd.Flags().Int("days", 30, "Number of days to show history for")
-	starHistoryCmd.Flags().String("format", "text", "Output format (text, json)")
+	statsCmd.Flags().String("format", output.FormatText, output.FormatUsage)
+	statsCmd.Flags().Bool("offline", stats.DefaultOptions().Offline, "Show the latest statistics stored locally without calling the GitHub API")
+	statsCmd.Flags().Bool("no-cache", stats.DefaultOptions().NoCache, "Neither read nor update the statistics stored locally")
+	statsHistoryCmd.Flags().Int("days", 0, "Number of days to show history for, 0 to show all recorded snapshots")
+	statsHistoryCmd.Flags().String("format", output.FormatText, output.FormatUsage)
+	statsResponseCmd.Flags().Int("days", stats.DefaultResponseOptions().Days, "Number of days of issues and pull requests to analyze")
+	statsResponseCmd.Flags().Bool("exclude-maintainers", stats.DefaultResponseOptions().ExcludeMaintainers, "Ignore issues and pull requests opened by maintainers, their responses are still counted")
+	statsResponseCmd.Flags().String("format", output.FormatText, output.FormatUsage)
+	statsContributorsCmd.Flags().Int("days", stats.DefaultContributorOptions().Days, "Number of days of the period to analyze")
+	statsContributorsCmd.Flags().Int("top", stats.DefaultContributorOptions().Top, "Number of top contributors to compute the share of contributions for")
+	statsContributorsCmd.Flags().String("format", output.FormatText, output.FormatUsage)
+	starHistoryCmd.Flags().Int("days", 30, "Number of days to show history for, 0 to show the whole history since the repository was created")
+	starHistoryCmd.Flags().String("format", output.FormatText, output.FormatUsage+", svg or png")
+	starHistoryCmd.Flags().StringP("output", "o", "", "Write the output to a file instead of stdout, except for the text format")
+	starHistoryCmd.Flags().StringSlice("compare", nil, "Other repositories to compare the star history with")
+	starHistoryCmd.Flags().String("interval", string(stats.IntervalDay), "Group the history by interval (day, week, month)")
+	starHistoryCmd.Flags().Bool("offline", stats.DefaultOptions().Offline, "Show the star history from the stargazers stored locally without calling the GitHub API")
+	starHistoryCmd.Flags().Bool("no-cache", stats.DefaultOptions().NoCache, "Neither read nor update the stargazers stored locally")
 }
@@ -28,7 +28,28 @@ const (
 )
 
 // Config represents the application configuration
-type Config struct{}
+type Config struct {
+	// Per-repository settings, keyed by owner/repo
+	Repos map[string]RepoConfig `yaml:"repos,omitempty"`
+}
+
+// RepoConfig represents the settings of a single repository
+type RepoConfig struct {
+	// Template used to render planning content, on local disk or inside the repository
+	PlanTemplate string `yaml:"plan_template,omitempty"`
+
+	// Template used to render onboarding content, on local disk or inside the repository
+	OnboardTemplate string `yaml:"onboard_template,omitempty"`
+
+	// Template used to render community activity digests, on local disk or inside the repository
+	DigestTemplate string `yaml:"digest_template,omitempty"`
+
+	// Template used to render release notes, on local disk or inside the repository
+	ReleaseNotesTemplate string `yaml:"release_notes_template,omitempty"`
+
+	// Template used to render the roadmap, on local disk or inside the repository
+	RoadmapTemplate string `yaml:"roadmap_template,omitempty"`
+}
 
 // State represents the application state
 type State struct {
@@ -97,6 +118,22 @@ func GetStateDir() string {
 	return stateDir
 }
 
+// GetDataDir returns OSP data directory for storing persistent data such as statistics snapshots
+func GetDataDir() string {
+	dataDir := filepath.Join(xdg.DataHome, AppName)
+	log.Debug("Data directory: %s", dataDir)
+
+	// Create data directory if it doesn't exist
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dataDir, DefaultDirMode); err != nil {
+			log.Debug("Failed to create data directory: %v", err)
+			return "."
+		}
+	}
+
+	return dataDir
+}
+
 // GetStateFile returns the path to the state file
 func GetStateFile() string {
 	return filepath.Join(GetStateDir(), StateFileName)
@@ -247,6 +284,14 @@ func SaveState(state *State) error {
 	return nil
 }
 
+// Repo returns the settings of the given repository, or empty settings if there are none
+func (c *Config) Repo(repoName string) RepoConfig {
+	if c == nil || c.Repos == nil {
+		return RepoConfig{}
+	}
+	return c.Repos[repoName]
+}
+
 // Load loads the configuration from file
 func Load(path string) (*Config, error) {
 	if path == "" {
@@ -0,0 +1,651 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/elliotxx/osp/pkg/util/issueutil"
+	"github.com/elliotxx/osp/pkg/util/tmplutil"
+)
+
+//go:embed templates/digest.gotmpl
+var templates embed.FS
+
+const (
+	// perPage is the page size used when listing from the GitHub API
+	perPage = 100
+
+	// maxSearchPages is the number of pages the GitHub search API returns at most
+	maxSearchPages = 10
+
+	// maxCommentPages is the maximum number of pages of comments listed for a digest
+	maxCommentPages = 10
+
+	// excerptLength is the maximum length of the excerpt of a notable comment
+	excerptLength = 120
+)
+
+// Manager handles community activity digests
+type Manager struct {
+	client    *api.RESTClient
+	gqlClient *api.GraphQLClient
+}
+
+// NewManager creates a new digest manager
+func NewManager(client *api.RESTClient, gqlClient *api.GraphQLClient) *Manager {
+	return &Manager{
+		client:    client,
+		gqlClient: gqlClient,
+	}
+}
+
+// User represents a GitHub user
+type User struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// IsBot reports whether the user is a bot account
+func (u User) IsBot() bool {
+	return u.Type == "Bot" || strings.HasSuffix(u.Login, "[bot]")
+}
+
+// Issue represents a GitHub issue or pull request
+type Issue struct {
+	Title     string     `json:"title"`
+	Number    int        `json:"number"`
+	State     string     `json:"state"`
+	User      User       `json:"user"`
+	HTMLURL   string     `json:"html_url"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+}
+
+// Release represents a GitHub release
+type Release struct {
+	Name        string     `json:"name"`
+	TagName     string     `json:"tag_name"`
+	HTMLURL     string     `json:"html_url"`
+	Draft       bool       `json:"draft"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// Comment represents a GitHub issue or pull request comment
+type Comment struct {
+	User      User      `json:"user"`
+	Body      string    `json:"body"`
+	HTMLURL   string    `json:"html_url"`
+	IssueURL  string    `json:"issue_url"`
+	CreatedAt time.Time `json:"created_at"`
+	Reactions struct {
+		TotalCount int `json:"total_count"`
+	} `json:"reactions"`
+}
+
+// IssueNumber returns the number of the issue or pull request the comment belongs to
+func (c Comment) IssueNumber() int {
+	number, _ := strconv.Atoi(c.IssueURL[strings.LastIndex(c.IssueURL, "/")+1:])
+	return number
+}
+
+// Excerpt returns the first line of the comment, shortened if it is too long
+func (c Comment) Excerpt() string {
+	line, _, _ := strings.Cut(strings.TrimSpace(c.Body), "\n")
+	line = strings.TrimSpace(line)
+	if runes := []rune(line); len(runes) > excerptLength {
+		line = string(runes[:excerptLength]) + "…"
+	}
+	return line
+}
+
+// Discussion represents a GitHub discussion
+type Discussion struct {
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Category  string    `json:"category"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Options represents digest options
+type Options struct {
+	DigestLabel        string    // Label used to locate the issue where the digest will be updated
+	TargetTitle        string    // Title template of the target issue or discussion
+	Days               int       // Number of days of activity to collect
+	Since              time.Time // Start of the activity to collect, overrides Days if set
+	DiscussionCategory string    // If set, publish the digest as a discussion in this category instead of an issue
+	NotableComments    int       // Maximum number of notable comments to include
+	Template           string    // Path of a custom digest template, on local disk or inside the repository
+	Diff               bool      // If true, only show the changes and return diff.ErrChanges if there are any
+	DryRun             bool      // If true, only show preview without making changes
+	AutoConfirm        bool      // If true, skip confirmation prompt
+}
+
+// DefaultOptions returns default digest options
+func DefaultOptions() Options {
+	return Options{
+		DigestLabel:     "digest",
+		TargetTitle:     `Community Digest: {{ .From.Format "2006-01-02" }} ~ {{ .To.Format "2006-01-02" }}`,
+		Days:            7,
+		NotableComments: 5,
+		Diff:            false,
+		DryRun:          false,
+		AutoConfirm:     false,
+	}
+}
+
+// TemplateData represents the data passed to the template
+type TemplateData struct {
+	From            time.Time
+	To              time.Time
+	OpenedIssues    []Issue
+	ClosedIssues    []Issue
+	MergedPRs       []Issue
+	Discussions     []Discussion
+	Releases        []Release
+	NotableComments []Comment
+	Contributors    []string
+	RepoOwner       string
+	RepoName        string
+}
+
+// target represents the existing issue or discussion holding the digest
+type target struct {
+	ID      string // node ID of a discussion
+	Number  int
+	Body    string
+	HTMLURL string
+}
+
+// Collect collects the community activity of the repository between from and to
+func (m *Manager) Collect(ctx context.Context, owner, repo string, from, to time.Time, opts Options) (TemplateData, error) {
+	data := TemplateData{From: from, To: to, RepoOwner: owner, RepoName: repo}
+	var err error
+
+	// Search issues and pull requests at the exact start time, like the other activities
+	since := from.UTC().Format(time.RFC3339)
+	data.OpenedIssues, err = m.search(fmt.Sprintf("repo:%s/%s is:issue created:>=%s", owner, repo, since))
+	if err != nil {
+		return data, fmt.Errorf("failed to search opened issues: %w", err)
+	}
+	data.ClosedIssues, err = m.search(fmt.Sprintf("repo:%s/%s is:issue is:closed closed:>=%s", owner, repo, since))
+	if err != nil {
+		return data, fmt.Errorf("failed to search closed issues: %w", err)
+	}
+	data.MergedPRs, err = m.search(fmt.Sprintf("repo:%s/%s is:pr is:merged merged:>=%s", owner, repo, since))
+	if err != nil {
+		return data, fmt.Errorf("failed to search merged pull requests: %w", err)
+	}
+	log.Debug("Found %d opened issues, %d closed issues and %d merged pull requests",
+		len(data.OpenedIssues), len(data.ClosedIssues), len(data.MergedPRs))
+
+	// Collect the authors of merged pull requests
+	contributors := make(map[string]bool)
+	for _, pr := range data.MergedPRs {
+		if !pr.User.IsBot() && !contributors[pr.User.Login] {
+			contributors[pr.User.Login] = true
+			data.Contributors = append(data.Contributors, pr.User.Login)
+		}
+	}
+	sort.Strings(data.Contributors)
+
+	// Get releases
+	var releases []Release
+	path := fmt.Sprintf("repos/%s/%s/releases?per_page=%d", owner, repo, perPage)
+	if err := m.client.Get(path, &releases); err != nil {
+		return data, fmt.Errorf("failed to list releases: %w", err)
+	}
+	for _, release := range releases {
+		if !release.Draft && release.PublishedAt != nil && !release.PublishedAt.Before(from) {
+			data.Releases = append(data.Releases, release)
+		}
+	}
+
+	// Get comments and pick the ones with the most reactions
+	comments, err := m.listComments(owner, repo, from)
+	if err != nil {
+		return data, err
+	}
+	data.NotableComments = selectNotableComments(comments, opts.NotableComments)
+
+	// Get discussions, which may be disabled for the repository
+	data.Discussions, err = m.listDiscussions(ctx, owner, repo, from)
+	if err != nil {
+		log.Warn("Failed to list discussions: %v", err)
+	}
+
+	return data, nil
+}
+
+// Update updates or creates the digest issue or discussion
+func (m *Manager) Update(ctx context.Context, owner, repo string, opts Options) error {
+	to := time.Now().UTC()
+	from := opts.Since
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -opts.Days)
+	}
+	log.Debug("Updating digest of %s/%s since %s", owner, repo, from.Format(time.RFC3339))
+
+	// Collect activity
+	data, err := m.Collect(ctx, owner, repo, from, to, opts)
+	if err != nil {
+		return err
+	}
+
+	// Load custom template if specified
+	var tmplText string
+	if opts.Template != "" {
+		tmplText, err = tmplutil.Load(m.client, fmt.Sprintf("%s/%s", owner, repo), opts.Template)
+		if err != nil {
+			return fmt.Errorf("failed to load digest template: %w", err)
+		}
+	}
+
+	// Generate digest content
+	content, err := generateContent(data, tmplText)
+	if err != nil {
+		return fmt.Errorf("failed to generate digest content: %w", err)
+	}
+	log.Debug("Generated digest content with %d bytes", len(content))
+
+	// Render digest title
+	tmpl, err := template.New("title").Parse(opts.TargetTitle)
+	if err != nil {
+		return fmt.Errorf("failed to parse title template: %w", err)
+	}
+	var titleBuf bytes.Buffer
+	if err := tmpl.Execute(&titleBuf, data); err != nil {
+		return fmt.Errorf("failed to execute title template: %w", err)
+	}
+	title := titleBuf.String()
+
+	// Find the existing digest
+	kind := "issue"
+	var existing *target
+	if opts.DiscussionCategory != "" {
+		kind = "discussion"
+		existing, err = m.findDiscussion(ctx, owner, repo, opts.DiscussionCategory, title)
+	} else {
+		existing, err = m.findIssue(owner, repo, opts.DigestLabel, title)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Create or update the digest
+	digest := issueutil.Target{
+		Kind:  "digest " + kind,
+		Title: title,
+		Save: func(content string) (*issueutil.Issue, error) {
+			var result *target
+			var err error
+			if opts.DiscussionCategory != "" {
+				result, err = m.saveDiscussion(ctx, owner, repo, opts.DiscussionCategory, existing, title, content)
+			} else {
+				result, err = m.saveIssue(owner, repo, opts.DigestLabel, existing, title, content)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &issueutil.Issue{Number: result.Number, Title: title, Body: result.Body, URL: result.HTMLURL}, nil
+		},
+	}
+	if existing != nil {
+		digest.Existing = &issueutil.Issue{Number: existing.Number, Title: title, Body: existing.Body, URL: existing.HTMLURL}
+	}
+	_, err = issueutil.Update(digest, content, issueutil.Options{DryRun: opts.DryRun, AutoConfirm: opts.AutoConfirm, Diff: opts.Diff})
+	return err
+}
+
+// search returns the issues and pull requests matching the search query
+func (m *Manager) search(query string) ([]Issue, error) {
+	var all []Issue
+	for page := 1; page <= maxSearchPages; page++ {
+		var result struct {
+			Items []Issue `json:"items"`
+		}
+		path := fmt.Sprintf("search/issues?q=%s&sort=created&order=asc&per_page=%d&page=%d", url.QueryEscape(query), perPage, page)
+		if err := m.client.Get(path, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Items...)
+		if len(result.Items) < perPage {
+			break
+		}
+	}
+	return all, nil
+}
+
+// listComments returns the issue and pull request comments created since from
+func (m *Manager) listComments(owner, repo string, from time.Time) ([]Comment, error) {
+	var all []Comment
+	for page := 1; page <= maxCommentPages; page++ {
+		var comments []Comment
+		path := fmt.Sprintf("repos/%s/%s/issues/comments?since=%s&per_page=%d&page=%d",
+			owner, repo, from.UTC().Format(time.RFC3339), perPage, page)
+		if err := m.client.Get(path, &comments); err != nil {
+			return nil, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, comment := range comments {
+			if !comment.CreatedAt.Before(from) {
+				all = append(all, comment)
+			}
+		}
+		if len(comments) < perPage {
+			break
+		}
+	}
+	return all, nil
+}
+
+// selectNotableComments returns up to n comments by humans with the most reactions
+func selectNotableComments(comments []Comment, n int) []Comment {
+	var notable []Comment
+	for _, comment := range comments {
+		if comment.Reactions.TotalCount > 0 && !comment.User.IsBot() {
+			notable = append(notable, comment)
+		}
+	}
+	sort.SliceStable(notable, func(i, j int) bool {
+		return notable[i].Reactions.TotalCount > notable[j].Reactions.TotalCount
+	})
+	if len(notable) > n {
+		notable = notable[:n]
+	}
+	return notable
+}
+
+// listDiscussions returns the discussions created since from
+func (m *Manager) listDiscussions(ctx context.Context, owner, repo string, from time.Time) ([]Discussion, error) {
+	query := `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 100, orderBy: {field: CREATED_AT, direction: DESC}) {
+      nodes { title url createdAt author { login } category { name } }
+    }
+  }
+}`
+	var response struct {
+		Repository struct {
+			Discussions struct {
+				Nodes []struct {
+					Title     string    `json:"title"`
+					URL       string    `json:"url"`
+					CreatedAt time.Time `json:"createdAt"`
+					Author    *User     `json:"author"`
+					Category  struct {
+						Name string `json:"name"`
+					} `json:"category"`
+				} `json:"nodes"`
+			} `json:"discussions"`
+		} `json:"repository"`
+	}
+	if err := m.gqlClient.DoWithContext(ctx, query, map[string]interface{}{"owner": owner, "name": repo}, &response); err != nil {
+		return nil, err
+	}
+
+	var discussions []Discussion
+	for _, node := range response.Repository.Discussions.Nodes {
+		if node.CreatedAt.Before(from) {
+			continue
+		}
+		discussion := Discussion{Title: node.Title, URL: node.URL, Category: node.Category.Name, CreatedAt: node.CreatedAt}
+		if node.Author != nil {
+			discussion.Author = node.Author.Login
+		}
+		discussions = append(discussions, discussion)
+	}
+	// List the oldest discussion first like the other activities
+	sort.Slice(discussions, func(i, j int) bool {
+		return discussions[i].CreatedAt.Before(discussions[j].CreatedAt)
+	})
+	return discussions, nil
+}
+
+// findIssue returns the oldest issue with the label and title, or nil if there is none
+func (m *Manager) findIssue(owner, repo, label, title string) (*target, error) {
+	var issues []Issue
+	path := fmt.Sprintf("repos/%s/%s/issues?labels=%s&state=all&per_page=%d", owner, repo, url.QueryEscape(label), perPage)
+	if err := m.client.Get(path, &issues); err != nil {
+		return nil, fmt.Errorf("failed to get existing digest issues: %w", err)
+	}
+	log.Debug("Found %d existing issues with digest label", len(issues))
+
+	var found *target
+	minNumber := math.MaxInt32
+	for _, issue := range issues {
+		if issue.Title == title && issue.Number < minNumber {
+			found = &target{Number: issue.Number, Body: issue.Body, HTMLURL: issue.HTMLURL}
+			minNumber = issue.Number
+		}
+	}
+	return found, nil
+}
+
+// saveIssue creates the digest issue, or updates the existing one
+func (m *Manager) saveIssue(owner, repo, label string, existing *target, title, content string) (*target, error) {
+	body := map[string]interface{}{
+		"title": title,
+		"body":  content,
+	}
+	if existing == nil {
+		body["labels"] = []string{label}
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	var response struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if existing == nil {
+		path := fmt.Sprintf("repos/%s/%s/issues", owner, repo)
+		if err := m.client.Post(path, bytes.NewReader(bodyBytes), &response); err != nil {
+			return nil, fmt.Errorf("failed to create digest issue: %w", err)
+		}
+	} else {
+		path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, existing.Number)
+		if err := m.client.Patch(path, bytes.NewReader(bodyBytes), &response); err != nil {
+			return nil, fmt.Errorf("failed to update digest issue: %w", err)
+		}
+	}
+	return &target{Number: response.Number, HTMLURL: response.HTMLURL}, nil
+}
+
+// findDiscussion returns the oldest recent discussion with the title in the
+// category, or nil if there is none
+func (m *Manager) findDiscussion(ctx context.Context, owner, repo, category, title string) (*target, error) {
+	repoID, categoryID, err := m.getDiscussionCategory(ctx, owner, repo, category)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Found discussion category '%s' (%s) in repository %s", category, categoryID, repoID)
+
+	query := `query($owner: String!, $name: String!, $categoryId: ID!) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 100, categoryId: $categoryId, orderBy: {field: CREATED_AT, direction: DESC}) {
+      nodes { id number title body url }
+    }
+  }
+}`
+	var response struct {
+		Repository struct {
+			Discussions struct {
+				Nodes []struct {
+					ID     string `json:"id"`
+					Number int    `json:"number"`
+					Title  string `json:"title"`
+					Body   string `json:"body"`
+					URL    string `json:"url"`
+				} `json:"nodes"`
+			} `json:"discussions"`
+		} `json:"repository"`
+	}
+	variables := map[string]interface{}{"owner": owner, "name": repo, "categoryId": categoryID}
+	if err := m.gqlClient.DoWithContext(ctx, query, variables, &response); err != nil {
+		return nil, fmt.Errorf("failed to get existing digest discussions: %w", err)
+	}
+
+	var found *target
+	minNumber := math.MaxInt32
+	for _, node := range response.Repository.Discussions.Nodes {
+		if node.Title == title && node.Number < minNumber {
+			found = &target{ID: node.ID, Number: node.Number, Body: node.Body, HTMLURL: node.URL}
+			minNumber = node.Number
+		}
+	}
+	return found, nil
+}
+
+// getDiscussionCategory returns the node IDs of the repository and the discussion category
+func (m *Manager) getDiscussionCategory(ctx context.Context, owner, repo, category string) (string, string, error) {
+	query := `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    id
+    discussionCategories(first: 100) { nodes { id name slug } }
+  }
+}`
+	var response struct {
+		Repository struct {
+			ID                   string `json:"id"`
+			DiscussionCategories struct {
+				Nodes []struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+					Slug string `json:"slug"`
+				} `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}
+	if err := m.gqlClient.DoWithContext(ctx, query, map[string]interface{}{"owner": owner, "name": repo}, &response); err != nil {
+		return "", "", fmt.Errorf("failed to get discussion categories: %w", err)
+	}
+	for _, node := range response.Repository.DiscussionCategories.Nodes {
+		if strings.EqualFold(node.Name, category) || strings.EqualFold(node.Slug, category) {
+			return response.Repository.ID, node.ID, nil
+		}
+	}
+	return "", "", fmt.Errorf("discussion category '%s' not found in %s/%s", category, owner, repo)
+}
+
+// saveDiscussion creates the digest discussion, or updates the existing one
+func (m *Manager) saveDiscussion(ctx context.Context, owner, repo, category string, existing *target, title, content string) (*target, error) {
+	var response struct {
+		Discussion struct {
+			Number int    `json:"number"`
+			URL    string `json:"url"`
+		} `json:"discussion"`
+	}
+
+	if existing == nil {
+		repoID, categoryID, err := m.getDiscussionCategory(ctx, owner, repo, category)
+		if err != nil {
+			return nil, err
+		}
+		mutation := `mutation($input: CreateDiscussionInput!) {
+  createDiscussion(input: $input) { discussion { number url } }
+}`
+		var created struct {
+			CreateDiscussion json.RawMessage `json:"createDiscussion"`
+		}
+		input := map[string]interface{}{"repositoryId": repoID, "categoryId": categoryID, "title": title, "body": content}
+		if err := m.gqlClient.DoWithContext(ctx, mutation, map[string]interface{}{"input": input}, &created); err != nil {
+			return nil, fmt.Errorf("failed to create digest discussion: %w", err)
+		}
+		if err := json.Unmarshal(created.CreateDiscussion, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse created discussion: %w", err)
+		}
+	} else {
+		mutation := `mutation($input: UpdateDiscussionInput!) {
+  updateDiscussion(input: $input) { discussion { number url } }
+}`
+		var updated struct {
+			UpdateDiscussion json.RawMessage `json:"updateDiscussion"`
+		}
+		input := map[string]interface{}{"discussionId": existing.ID, "title": title, "body": content}
+		if err := m.gqlClient.DoWithContext(ctx, mutation, map[string]interface{}{"input": input}, &updated); err != nil {
+			return nil, fmt.Errorf("failed to update digest discussion: %w", err)
+		}
+		if err := json.Unmarshal(updated.UpdateDiscussion, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse updated discussion: %w", err)
+		}
+	}
+
+	return &target{Number: response.Discussion.Number, HTMLURL: response.Discussion.URL}, nil
+}
+
+// generateContent generates the digest content using the template. If tmplText
+// is empty, the built-in template is used. The content carries a hidden hash
+// marker computed over the content rendered at a fixed time.
+func generateContent(data TemplateData, tmplText string) (string, error) {
+	content, err := generateContentWithTime(data, tmplText, time.Now())
+	if err != nil {
+		return "", err
+	}
+	stable, err := generateContentWithTime(data, tmplText, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	return hashutil.Mark(content, hashutil.Sum(stable)), nil
+}
+
+// generateContentWithTime generates the digest content using the template with a fixed time
+func generateContentWithTime(data TemplateData, tmplText string, now time.Time) (string, error) {
+	funcMap := template.FuncMap{
+		"now": func() string {
+			return now.UTC().Format("January 2, 2006 15:04 MST")
+		},
+		"formatDate": func(date interface{}) string {
+			switch d := date.(type) {
+			case time.Time:
+				return d.Format("January 2, 2006")
+			case *time.Time:
+				if d != nil {
+					return d.Format("January 2, 2006")
+				}
+			}
+			return ""
+		},
+		"urlEncode": url.QueryEscape,
+	}
+
+	// Load template with functions
+	tmpl := template.New("digest.gotmpl").Funcs(funcMap)
+	var err error
+	if tmplText == "" {
+		tmpl, err = tmpl.ParseFS(templates, "templates/digest.gotmpl")
+	} else {
+		tmpl, err = tmpl.Parse(tmplText)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// Check the template against the template data before rendering
+	if err := tmplutil.Validate(tmpl, data); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	// Execute template
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,81 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newComment(login string, reactions int, body string) Comment {
+	c := Comment{
+		User:     User{Login: login},
+		Body:     body,
+		HTMLURL:  "https://github.com/owner/repo/issues/7#issuecomment-1",
+		IssueURL: "https://api.github.com/repos/owner/repo/issues/7",
+	}
+	c.Reactions.TotalCount = reactions
+	return c
+}
+
+func TestGenerateContent(t *testing.T) {
+	published := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	data := TemplateData{
+		From:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC),
+		OpenedIssues: []Issue{{Number: 1, Title: "Crash on start", User: User{Login: "alice"}}},
+		MergedPRs:    []Issue{{Number: 2, Title: "Fix crash", User: User{Login: "bob"}}},
+		Releases:     []Release{{TagName: "v1.0.0", HTMLURL: "https://github.com/owner/repo/releases/v1.0.0", PublishedAt: &published}},
+		NotableComments: []Comment{
+			newComment("carol", 3, "Great idea!\nMore details"),
+		},
+		Contributors: []string{"bob"},
+		RepoOwner:    "owner",
+		RepoName:     "repo",
+	}
+
+	content, err := generateContentWithTime(data, "", time.Time{})
+	assert.NoError(t, err)
+	assert.Contains(t, content, "from January 1, 2025 to January 8, 2025")
+	assert.Contains(t, content, "- [v1.0.0](https://github.com/owner/repo/releases/v1.0.0) (January 3, 2025)")
+	assert.Contains(t, content, "- #2 Fix crash (@bob)")
+	assert.Contains(t, content, "- #1 Crash on start (@alice)")
+	assert.Contains(t, content, "- @carol on [#7](https://github.com/owner/repo/issues/7#issuecomment-1) (3 reactions): Great idea!")
+	assert.NotContains(t, content, "## Closed Issues")
+	assert.NotContains(t, content, "## New Discussions")
+
+	// The hash only changes with the activity
+	marked, err := generateContent(data, "")
+	assert.NoError(t, err)
+	other, err := generateContent(data, "")
+	assert.NoError(t, err)
+	assert.True(t, hashutil.Match(marked, other))
+
+	_, err = generateContent(data, "{{ .Missing }}")
+	assert.Error(t, err)
+}
+
+func TestSelectNotableComments(t *testing.T) {
+	comments := []Comment{
+		newComment("alice", 1, "a"),
+		newComment("bob", 0, "b"),
+		newComment("github-actions[bot]", 10, "c"),
+		newComment("carol", 5, "d"),
+		newComment("dave", 2, "e"),
+	}
+
+	notable := selectNotableComments(comments, 2)
+	logins := make([]string, 0, len(notable))
+	for _, c := range notable {
+		logins = append(logins, c.User.Login)
+	}
+	assert.Equal(t, []string{"carol", "dave"}, logins)
+}
+
+func TestCommentExcerpt(t *testing.T) {
+	c := newComment("alice", 1, "  "+strings.Repeat("x", excerptLength+5)+"\nsecond line")
+	assert.Equal(t, strings.Repeat("x", excerptLength)+"…", c.Excerpt())
+	assert.Equal(t, 7, c.IssueNumber())
+}
@@ -410,6 +410,19 @@ func Bold(text string) string {
 	return styleBold + text + colorReset
 }
 
+// Colorize wraps text in the color if color output is enabled
+//
+// Example:
+//
+//	fmt.Println(log.Colorize(log.ColorGreen, "+10"))
+//	// Output: +10 (in green, or plain when color output is disabled)
+func Colorize(color, text string) string {
+	if noColor || color == "" {
+		return text
+	}
+	return color + text + colorReset
+}
+
 // Color constants for use with C() method
 var (
 	ColorReset  = colorReset
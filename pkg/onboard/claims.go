@@ -0,0 +1,319 @@
+package onboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/util/prompt"
+)
+
+// claimReminderMarker marks the reminder comments posted on stale claims
+const claimReminderMarker = "<!-- osp:claim-reminder -->"
+
+// ClaimAction represents what is done with a claim
+type ClaimAction string
+
+const (
+	// ClaimActive means the assignee has been active recently
+	ClaimActive ClaimAction = "active"
+	// ClaimRemind means a reminder is posted to the assignee
+	ClaimRemind ClaimAction = "remind"
+	// ClaimWait means the assignee has been reminded and is within the grace period
+	ClaimWait ClaimAction = "wait"
+	// ClaimRelease means the assignee is unassigned after the grace period
+	ClaimRelease ClaimAction = "release"
+)
+
+// Claim represents an open onboarding issue assigned to someone
+type Claim struct {
+	Issue        OnboardIssue
+	LastActivity time.Time  // Latest of the assignment and the comments, pull request updates and commits of the assignee
+	RemindedAt   *time.Time // Time of the reminder posted since the last activity, if any
+	Action       ClaimAction
+}
+
+// timelineEvent represents an event of the timeline of an issue
+type timelineEvent struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Body      string    `json:"body"`
+	CommitID  string    `json:"commit_id"`
+	User      *struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Actor *struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+	Assignee *struct {
+		Login string `json:"login"`
+	} `json:"assignee"`
+	Source *struct {
+		Issue *struct {
+			State     string    `json:"state"`
+			UpdatedAt time.Time `json:"updated_at"`
+			User      struct {
+				Login string `json:"login"`
+			} `json:"user"`
+			PullRequest *struct{} `json:"pull_request"`
+		} `json:"issue"`
+	} `json:"source"`
+}
+
+// UpdateClaims finds the open onboarding issues whose assignee has been inactive
+// for opts.StaleClaimDays. The assignee is reminded first, and is unassigned
+// after opts.ClaimGraceDays more days without activity, putting opts.ReleaseLabel
+// back on the issue.
+func (m *Manager) UpdateClaims(ctx context.Context, repoName string, opts Options) error {
+	if opts.StaleClaimDays <= 0 {
+		return fmt.Errorf("stale claim days must be positive")
+	}
+
+	issues, err := m.SearchOnboardIssues(ctx, repoName, opts)
+	if err != nil {
+		return fmt.Errorf("failed to search onboarding issues: %w", err)
+	}
+
+	// Check the activity of the assignee of each open issue
+	now := time.Now()
+	var claims []Claim
+	for _, issue := range issues {
+		if issue.Status != "open" || issue.Assignee == "" {
+			continue
+		}
+		// Unassigning one of several assignees would not make the issue available again
+		if len(issue.Assignees) > 1 {
+			log.Debug("Skipping claim of #%d, which is shared by %d assignees", issue.Number, len(issue.Assignees))
+			continue
+		}
+		events, err := m.listTimeline(repoName, issue.Number)
+		if err != nil {
+			return err
+		}
+		claim := evaluateClaim(issue, events, now, opts)
+		log.Debug("Claim of #%d by @%s: %s (last activity: %s)", issue.Number, issue.Assignee, claim.Action, claim.LastActivity.Format(time.RFC3339))
+		if claim.Action == ClaimRemind || claim.Action == ClaimRelease {
+			claims = append(claims, claim)
+		}
+	}
+
+	if len(claims) == 0 {
+		log.Success("No stale claims found")
+		return nil
+	}
+
+	// Show the planned actions
+	log.C(log.ColorBlue).P("↓").Log("Stale claims:")
+	for _, claim := range claims {
+		action := "remind"
+		if claim.Action == ClaimRelease {
+			action = fmt.Sprintf("unassign and label '%s'", opts.ReleaseLabel)
+		}
+		log.L(1).P("→").Log("#%d @%s, inactive since %s: %s", claim.Issue.Number, claim.Issue.Assignee, claim.LastActivity.Format("2006-01-02"), action)
+	}
+
+	if opts.DryRun {
+		log.Warn("Dry-run mode, skipping update")
+		return nil
+	}
+
+	// Ask for confirmation if auto-confirm is not enabled
+	if !opts.AutoConfirm {
+		confirmed, err := prompt.AskForConfirmation("Do you want to proceed with the update?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info("Update cancelled")
+			return nil
+		}
+	} else {
+		log.Warn("Auto-confirm is enabled, skipping confirmation")
+	}
+
+	for _, claim := range claims {
+		switch claim.Action {
+		case ClaimRemind:
+			err = m.remindClaim(repoName, claim, opts)
+		case ClaimRelease:
+			err = m.releaseClaim(repoName, claim, opts)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateClaim decides what to do with the claim of an issue from its timeline
+func evaluateClaim(issue OnboardIssue, events []timelineEvent, now time.Time, opts Options) Claim {
+	claim := Claim{Issue: issue, LastActivity: lastAssigneeActivity(issue, events)}
+	for _, event := range events {
+		if event.Event == "commented" && strings.Contains(event.Body, claimReminderMarker) && event.CreatedAt.After(claim.LastActivity) {
+			remindedAt := event.CreatedAt
+			claim.RemindedAt = &remindedAt
+		}
+	}
+
+	switch {
+	case !isStaleClaim(issue, events, now.AddDate(0, 0, -opts.StaleClaimDays)):
+		claim.Action = ClaimActive
+	case claim.RemindedAt == nil:
+		claim.Action = ClaimRemind
+	case claim.RemindedAt.After(now.AddDate(0, 0, -opts.ClaimGraceDays)):
+		claim.Action = ClaimWait
+	default:
+		claim.Action = ClaimRelease
+	}
+	return claim
+}
+
+// markStaleClaims marks the open issues whose assignee has been inactive for
+// opts.StaleClaimDays as stale claims
+func (m *Manager) markStaleClaims(repoName string, issues []OnboardIssue, now time.Time, opts Options) error {
+	staleBefore := now.AddDate(0, 0, -opts.StaleClaimDays)
+	for i := range issues {
+		if issues[i].Status != "open" || issues[i].Assignee == "" {
+			continue
+		}
+		events, err := m.listTimeline(repoName, issues[i].Number)
+		if err != nil {
+			return err
+		}
+		issues[i].StaleClaim = isStaleClaim(issues[i], events, staleBefore)
+	}
+	return nil
+}
+
+// isStaleClaim reports whether the issue is open and assigned, but its assignee
+// has not been active on it since staleBefore
+func isStaleClaim(issue OnboardIssue, events []timelineEvent, staleBefore time.Time) bool {
+	return issue.Status == "open" && issue.Assignee != "" && lastAssigneeActivity(issue, events).Before(staleBefore)
+}
+
+// lastAssigneeActivity returns the time of the latest activity of the assignee
+// on the issue, or the creation time of the issue if there is none
+func lastAssigneeActivity(issue OnboardIssue, events []timelineEvent) time.Time {
+	last := issue.CreatedAt
+	for _, event := range events {
+		if at, ok := assigneeActivityAt(event, issue.Assignee); ok && at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// assigneeActivityAt returns the time of the activity of the assignee in the
+// event: the assignment of the issue to the assignee, a comment, a commit
+// referencing the issue, or a pull request of the assignee referencing or
+// closing the issue. An open pull request is active until its last update.
+func assigneeActivityAt(event timelineEvent, assignee string) (time.Time, bool) {
+	switch event.Event {
+	case "assigned":
+		if event.Assignee != nil && strings.EqualFold(event.Assignee.Login, assignee) {
+			return event.CreatedAt, true
+		}
+	case "commented":
+		if event.User != nil && strings.EqualFold(event.User.Login, assignee) {
+			return event.CreatedAt, true
+		}
+	case "cross-referenced":
+		if event.Source == nil || event.Source.Issue == nil {
+			break
+		}
+		pr := event.Source.Issue
+		if pr.PullRequest == nil || !strings.EqualFold(pr.User.Login, assignee) {
+			break
+		}
+		if pr.State == "open" && pr.UpdatedAt.After(event.CreatedAt) {
+			return pr.UpdatedAt, true
+		}
+		return event.CreatedAt, true
+	case "referenced":
+		if event.CommitID != "" && event.Actor != nil && strings.EqualFold(event.Actor.Login, assignee) {
+			return event.CreatedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// listTimeline returns the timeline events of an issue
+func (m *Manager) listTimeline(repoName string, number int) ([]timelineEvent, error) {
+	var allEvents []timelineEvent
+	for page := 1; ; page++ {
+		var events []timelineEvent
+		path := fmt.Sprintf("repos/%s/issues/%d/timeline?page=%d&per_page=100", repoName, number, page)
+		if err := m.client.Get(path, &events); err != nil {
+			return nil, fmt.Errorf("failed to get timeline of issue #%d: %w", number, err)
+		}
+		allEvents = append(allEvents, events...)
+		if len(events) < 100 {
+			break
+		}
+	}
+	return allEvents, nil
+}
+
+// remindClaim posts a reminder to the assignee of the claim
+func (m *Manager) remindClaim(repoName string, claim Claim, opts Options) error {
+	body := fmt.Sprintf("Hi @%s, thanks for picking up this issue! 👋\n\n"+
+		"We haven't seen any activity here for %d days. Are you still working on it? If you are stuck, "+
+		"feel free to ask for help in this issue. Without any update within %d days, the issue will be "+
+		"unassigned so that other contributors can pick it up.\n\n%s",
+		claim.Issue.Assignee, opts.StaleClaimDays, opts.ClaimGraceDays, claimReminderMarker)
+	if err := m.comment(repoName, claim.Issue.Number, body); err != nil {
+		return err
+	}
+	log.Success("Reminded @%s on issue #%d", claim.Issue.Assignee, claim.Issue.Number)
+	return nil
+}
+
+// releaseClaim unassigns the assignee of the claim and makes the issue available again
+func (m *Manager) releaseClaim(repoName string, claim Claim, opts Options) error {
+	number := claim.Issue.Number
+	bodyBytes, err := json.Marshal(map[string]interface{}{"assignees": []string{claim.Issue.Assignee}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	path := fmt.Sprintf("repos/%s/issues/%d/assignees", repoName, number)
+	if err := m.client.Do("DELETE", path, bytes.NewReader(bodyBytes), nil); err != nil {
+		return fmt.Errorf("failed to unassign @%s from issue #%d: %w", claim.Issue.Assignee, number, err)
+	}
+
+	if opts.ReleaseLabel != "" {
+		bodyBytes, err := json.Marshal(map[string]interface{}{"labels": []string{opts.ReleaseLabel}})
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		path := fmt.Sprintf("repos/%s/issues/%d/labels", repoName, number)
+		if err := m.client.Post(path, bytes.NewReader(bodyBytes), nil); err != nil {
+			return fmt.Errorf("failed to add label to issue #%d: %w", number, err)
+		}
+	}
+
+	body := fmt.Sprintf("This issue has been unassigned after %d days without activity, and is open to "+
+		"other contributors again. @%s, thank you for your interest, feel free to pick it up again anytime! 🙏",
+		opts.StaleClaimDays+opts.ClaimGraceDays, claim.Issue.Assignee)
+	if err := m.comment(repoName, number, body); err != nil {
+		return err
+	}
+	log.Success("Unassigned @%s from issue #%d", claim.Issue.Assignee, number)
+	return nil
+}
+
+// comment posts a comment on an issue
+func (m *Manager) comment(repoName string, number int, body string) error {
+	bodyBytes, err := json.Marshal(map[string]interface{}{"body": body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	path := fmt.Sprintf("repos/%s/issues/%d/comments", repoName, number)
+	if err := m.client.Post(path, bytes.NewReader(bodyBytes), nil); err != nil {
+		return fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+	}
+	return nil
+}
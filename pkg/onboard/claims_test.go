@@ -0,0 +1,133 @@
+package onboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssigneeActivityAt(t *testing.T) {
+	var events []timelineEvent
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"event": "assigned", "created_at": "2025-03-01T00:00:00Z", "assignee": {"login": "alice"}},
+		{"event": "assigned", "created_at": "2025-03-01T00:00:00Z", "assignee": {"login": "bob"}},
+		{"event": "commented", "created_at": "2025-03-02T00:00:00Z", "user": {"login": "Alice"}},
+		{"event": "commented", "created_at": "2025-03-02T00:00:00Z", "user": {"login": "bob"}},
+		{"event": "cross-referenced", "created_at": "2025-03-03T00:00:00Z", "source": {"issue": {"state": "closed", "updated_at": "2025-03-20T00:00:00Z", "user": {"login": "alice"}, "pull_request": {}}}},
+		{"event": "cross-referenced", "created_at": "2025-03-03T00:00:00Z", "source": {"issue": {"state": "open", "updated_at": "2025-03-20T00:00:00Z", "user": {"login": "alice"}, "pull_request": {}}}},
+		{"event": "cross-referenced", "created_at": "2025-03-03T00:00:00Z", "source": {"issue": {"state": "open", "user": {"login": "alice"}}}},
+		{"event": "referenced", "created_at": "2025-03-04T00:00:00Z", "actor": {"login": "alice"}, "commit_id": "abc123"},
+		{"event": "referenced", "created_at": "2025-03-04T00:00:00Z", "actor": {"login": "alice"}},
+		{"event": "labeled", "created_at": "2025-03-05T00:00:00Z", "actor": {"login": "alice"}}
+	]`), &events))
+
+	var got []string
+	for _, event := range events {
+		at, ok := assigneeActivityAt(event, "alice")
+		if !ok {
+			got = append(got, "")
+			continue
+		}
+		got = append(got, at.Format("2006-01-02"))
+	}
+	assert.Equal(t, []string{"2025-03-01", "", "2025-03-02", "", "2025-03-03", "2025-03-20", "", "2025-03-04", "", ""}, got)
+}
+
+func TestEvaluateClaim(t *testing.T) {
+	now := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+	assigned := func(days int) timelineEvent {
+		event := timelineEvent{Event: "assigned", CreatedAt: daysAgo(days)}
+		event.Assignee = &struct {
+			Login string `json:"login"`
+		}{Login: "alice"}
+		return event
+	}
+	pullRequest := func(days int, state string, updatedDays int) timelineEvent {
+		var event timelineEvent
+		require.NoError(t, json.Unmarshal([]byte(`{"event": "cross-referenced", "source": {"issue": {"user": {"login": "alice"}, "pull_request": {}}}}`), &event))
+		event.CreatedAt = daysAgo(days)
+		event.Source.Issue.State = state
+		event.Source.Issue.UpdatedAt = daysAgo(updatedDays)
+		return event
+	}
+	reminded := func(days int) timelineEvent {
+		return timelineEvent{Event: "commented", CreatedAt: daysAgo(days), Body: "Still working on it?\n\n" + claimReminderMarker}
+	}
+	issue := OnboardIssue{Number: 1, Status: "open", Assignee: "alice", CreatedAt: daysAgo(100)}
+	opts := DefaultOptions()
+	opts.StaleClaimDays = 30
+	opts.ClaimGraceDays = 7
+
+	tests := []struct {
+		name   string
+		events []timelineEvent
+		want   ClaimAction
+	}{
+		{name: "recently assigned", events: []timelineEvent{assigned(10)}, want: ClaimActive},
+		{name: "inactive", events: []timelineEvent{assigned(40)}, want: ClaimRemind},
+		{name: "reminded", events: []timelineEvent{assigned(40), reminded(3)}, want: ClaimWait},
+		{name: "grace period over", events: []timelineEvent{assigned(50), reminded(10)}, want: ClaimRelease},
+		{name: "reminder before activity", events: []timelineEvent{reminded(60), assigned(40)}, want: ClaimRemind},
+		{name: "open pull request updated recently", events: []timelineEvent{assigned(60), pullRequest(50, "open", 5), reminded(3)}, want: ClaimActive},
+		{name: "closed pull request", events: []timelineEvent{assigned(60), pullRequest(50, "closed", 5)}, want: ClaimRemind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := evaluateClaim(issue, tt.events, now, opts)
+			assert.Equal(t, tt.want, claim.Action)
+		})
+	}
+}
+
+// redirectTransport sends the requests to the GitHub API to a test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestUpdateClaimsSkipsSharedIssues(t *testing.T) {
+	timelines := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search/issues":
+			_, _ = w.Write([]byte(`{"items": [
+				{"number": 1, "state": "open", "assignee": {"login": "alice"}, "assignees": [{"login": "alice"}, {"login": "bob"}], "created_at": "2020-01-01T00:00:00Z"},
+				{"number": 2, "state": "open", "assignee": {"login": "alice"}, "assignees": [{"login": "alice"}], "created_at": "2020-01-01T00:00:00Z"}
+			]}`))
+		case strings.HasSuffix(r.URL.Path, "/timeline"):
+			timelines[r.URL.Path]++
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: redirectTransport{target: target}})
+	require.NoError(t, err)
+
+	opts := DefaultOptions()
+	opts.DryRun = true
+	require.NoError(t, (&Manager{client: client}).UpdateClaims(context.Background(), "owner/repo", opts))
+
+	// Only the claim of the issue with a single assignee is evaluated
+	assert.Equal(t, map[string]int{"/repos/owner/repo/issues/2/timeline": 1}, timelines)
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/elliotxx/osp/pkg/config"
 	"github.com/elliotxx/osp/pkg/log"
-	"github.com/elliotxx/osp/pkg/util/prompt"
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/elliotxx/osp/pkg/util/issueutil"
+	"github.com/elliotxx/osp/pkg/util/tmplutil"
 )
 
 //go:embed templates/*.gotmpl
@@ -42,11 +44,18 @@ func NewManager(client *api.RESTClient) (*Manager, error) {
 
 // OnboardIssue represents an issue suitable for new contributors
 type OnboardIssue struct {
-	Difficulty string `json:"difficulty"` // Easy, Medium, Hard
-	Status     string `json:"status"`     // open, closed
-	Assignee   string `json:"assignee,omitempty"`
-	Number     int    `json:"number"` // Issue number for sorting
-	Category   string `json:"category"`
+	Difficulty string    `json:"difficulty"` // Easy, Medium, Hard
+	Status     string    `json:"status"`     // open, closed
+	Assignee   string    `json:"assignee,omitempty"`
+	Assignees  []string  `json:"assignees,omitempty"` // All assignees, the first one is Assignee
+	Number     int       `json:"number"`              // Issue number for sorting
+	Category   string    `json:"category"`
+	Title      string    `json:"title"`
+	HTMLURL    string    `json:"html_url"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Comments   int       `json:"comments"`
+	StaleClaim bool      `json:"stale_claim"` // true if the issue is open and its assignee has been inactive for a while
 }
 
 // Options represents the options for onboarding
@@ -59,8 +68,16 @@ type Options struct {
 	// Target issue configuration
 	TargetLabel string // Label used to locate the issue where onboarding content will be updated
 	TargetTitle string // Title of the target issue where onboarding content will be updated
+	Template    string // Path of a custom onboarding template, on local disk or inside the repository
+
+	// Claim configuration
+	StaleClaimDays int    // Days without activity of the assignee after which an assigned open issue is considered a stale claim
+	ClaimGraceDays int    // Days after the reminder of a stale claim before the assignee is unassigned
+	ReleaseLabel   string // Label added back to an issue when its assignee is unassigned
+	MaxClaims      int    // Maximum number of open onboarding issues a contributor can claim at once, 0 for no limit
 
 	// Command behavior
+	Diff        bool // If true, only show the changes and return diff.ErrChanges if there are any
 	DryRun      bool // If true, only show preview without making changes
 	AutoConfirm bool // If true, skip confirmation prompt
 }
@@ -77,7 +94,14 @@ func DefaultOptions() Options {
 		TargetLabel: "onboarding",
 		TargetTitle: "Onboarding: Getting Started with Contributing",
 
+		// Claim defaults
+		StaleClaimDays: 30,
+		ClaimGraceDays: 7,
+		ReleaseLabel:   "help wanted",
+		MaxClaims:      2,
+
 		// Command behavior defaults
+		Diff:        false,
 		DryRun:      false,
 		AutoConfirm: false,
 	}
@@ -142,6 +166,12 @@ func (m *Manager) SearchOnboardIssues(_ context.Context, repoName string, opts O
 		Assignee *struct {
 			Login string `json:"login"`
 		} `json:"assignee"`
+		Assignees []struct {
+			Login string `json:"login"`
+		} `json:"assignees"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+		Comments  int       `json:"comments"`
 	}
 
 	page := 1
@@ -160,6 +190,12 @@ func (m *Manager) SearchOnboardIssues(_ context.Context, repoName string, opts O
 				Assignee *struct {
 					Login string `json:"login"`
 				} `json:"assignee"`
+				Assignees []struct {
+					Login string `json:"login"`
+				} `json:"assignees"`
+				CreatedAt time.Time `json:"created_at"`
+				UpdatedAt time.Time `json:"updated_at"`
+				Comments  int       `json:"comments"`
 			} `json:"items"`
 		}
 
@@ -226,6 +262,14 @@ func (m *Manager) SearchOnboardIssues(_ context.Context, repoName string, opts O
 				}
 				return ""
 			}(),
+			Title:     issue.Title,
+			HTMLURL:   issue.HTMLURL,
+			CreatedAt: issue.CreatedAt,
+			UpdatedAt: issue.UpdatedAt,
+			Comments:  issue.Comments,
+		}
+		for _, assignee := range issue.Assignees {
+			onboardIssue.Assignees = append(onboardIssue.Assignees, assignee.Login)
 		}
 		issues = append(issues, onboardIssue)
 		log.Debug("Added issue: (Difficulty: %s, Status: %s)", onboardIssue.Difficulty, onboardIssue.Status)
@@ -234,19 +278,49 @@ func (m *Manager) SearchOnboardIssues(_ context.Context, repoName string, opts O
 	return issues, nil
 }
 
-// GenerateContent generates the complete content using the template
+// GenerateContent generates the complete content using the template. The content
+// carries a hidden hash marker, which is computed over the content rendered at a
+// fixed time so that it only changes when the onboarding issues change.
 func (m *Manager) GenerateContent(issues []OnboardIssue, repoName string, opts Options) (string, error) {
+	// Load custom template if specified
+	var tmplText string
+	if opts.Template != "" {
+		var err error
+		tmplText, err = tmplutil.Load(m.client, repoName, opts.Template)
+		if err != nil {
+			return "", fmt.Errorf("failed to load template: %w", err)
+		}
+	}
+
+	content, err := m.generateContentWithTime(issues, repoName, opts, tmplText, time.Now())
+	if err != nil {
+		return "", err
+	}
+	stable, err := m.generateContentWithTime(issues, repoName, opts, tmplText, time.Time{})
+	if err != nil {
+		return "", err
+	}
+
+	return hashutil.Mark(content, hashutil.Sum(stable)), nil
+}
+
+// generateContentWithTime generates the complete content using the template with a fixed time.
+// If tmplText is empty, the built-in template is used.
+func (m *Manager) generateContentWithTime(issues []OnboardIssue, repoName string, opts Options, tmplText string, now time.Time) (string, error) {
 	// Load template
 	log.Debug("Loading template...")
 	tmpl := template.New("onboard.gotmpl").Funcs(template.FuncMap{
 		"now": func() string {
-			return time.Now().UTC().Format("January 2, 2006 15:04 MST")
+			return now.UTC().Format("January 2, 2006 15:04 MST")
 		},
 		"urlEncode":           url.QueryEscape,
 		"generateProgressBar": generateProgressBar,
 		"add": func(a, b int) int {
 			return a + b
 		},
+		"age": func(t time.Time) string {
+			return formatAge(now, t)
+		},
 		"hasUnspecifiedIssues": func(issuesByCategory map[string]map[string][]OnboardIssue) bool {
 			if categoryMap, ok := issuesByCategory[""]; ok {
 				for _, issues := range categoryMap {
@@ -259,7 +333,12 @@ func (m *Manager) GenerateContent(issues []OnboardIssue, repoName string, opts O
 		},
 	})
 
-	tmpl, err := tmpl.ParseFS(templatesFS, "templates/*.gotmpl")
+	var err error
+	if tmplText == "" {
+		tmpl, err = tmpl.ParseFS(templatesFS, "templates/*.gotmpl")
+	} else {
+		tmpl, err = tmpl.Parse(tmplText)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -363,19 +442,26 @@ func (m *Manager) GenerateContent(issues []OnboardIssue, repoName string, opts O
 	}
 	sort.Strings(stats.Contributors)
 
-	// Create a buffer to store the output
-	var buf strings.Builder
-
-	// Execute template
-	log.Debug("Executing template...")
-	err = tmpl.ExecuteTemplate(&buf, "onboard.gotmpl", TemplateData{
+	data := TemplateData{
 		RepoName:         repoName,
 		IssuesByCategory: issuesByDiffCategory,
 		DifficultyLabels: opts.DifficultyLabels, // 不包含空字符串，让模版决定何时显示未指定难度的 issue
 		CategoryLabels:   opts.CategoryLabels,
 		Stats:            stats,
 		OnboardLabels:    opts.OnboardLabels,
-	})
+	}
+
+	// Check the template against the template data before rendering
+	if err := tmplutil.Validate(tmpl, data); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	// Create a buffer to store the output
+	var buf strings.Builder
+
+	// Execute template
+	log.Debug("Executing template...")
+	err = tmpl.ExecuteTemplate(&buf, "onboard.gotmpl", data)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
@@ -383,6 +469,31 @@ func (m *Manager) GenerateContent(issues []OnboardIssue, repoName string, opts O
 	return buf.String(), nil
 }
 
+// formatAge describes how long ago t was compared to now, e.g. "3 days ago".
+// It is empty for a zero now, so that the hash of the content ignores ages.
+func formatAge(now, t time.Time) string {
+	if now.IsZero() || t.IsZero() {
+		return ""
+	}
+	days := int(now.Sub(t).Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days == 1:
+		return "1 day ago"
+	case days < 30:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 60:
+		return "1 month ago"
+	case days < 365:
+		return fmt.Sprintf("%d months ago", days/30)
+	case days < 730:
+		return "1 year ago"
+	default:
+		return fmt.Sprintf("%d years ago", days/365)
+	}
+}
+
 // generateProgressBar generates a progress bar string based on completion percentage
 func generateProgressBar(completed, total int) string {
 	const width = 20 // Total width of the progress bar
@@ -419,6 +530,13 @@ func (m *Manager) Update(ctx context.Context, repoName string, opts Options) err
 		return fmt.Errorf("failed to search onboarding issues: %w", err)
 	}
 
+	// Mark the claims whose assignee has been inactive
+	if opts.StaleClaimDays > 0 {
+		if err := m.markStaleClaims(repoName, issues, time.Now(), opts); err != nil {
+			return err
+		}
+	}
+
 	content, err := m.GenerateContent(issues, repoName, opts)
 	if err != nil {
 		return fmt.Errorf("failed to generate onboarding content: %w", err)
@@ -430,6 +548,7 @@ func (m *Manager) Update(ctx context.Context, repoName string, opts Options) err
 	var existingIssues []struct {
 		Title  string `json:"title"`
 		Number int    `json:"number"`
+		Body   string `json:"body"`
 	}
 	err = m.client.Get(path, &existingIssues)
 	if err != nil {
@@ -441,6 +560,7 @@ func (m *Manager) Update(ctx context.Context, repoName string, opts Options) err
 	var onboardingIssue *struct {
 		Title  string `json:"title"`
 		Number int    `json:"number"`
+		Body   string `json:"body"`
 	}
 	if len(existingIssues) > 0 {
 		onboardingIssue = &existingIssues[0]
@@ -456,87 +576,45 @@ func (m *Manager) Update(ctx context.Context, repoName string, opts Options) err
 		log.Warn("Found multiple onboarding issues, will update issue #%d", onboardingIssue.Number)
 	}
 
-	// Show preview
-	if onboardingIssue == nil {
-		log.Info("Creating new onboarding issue")
-	} else {
-		log.Info("Updating existing onboarding issue #%d", onboardingIssue.Number)
-	}
-
-	// Preview the content
-	log.C(log.ColorBlue).P("↓").Log("Preview of the onboarding content:")
-	log.C(log.ColorCyan).Log("%s", content)
-
-	if !opts.DryRun {
-		// Ask for confirmation if auto-confirm is not enabled
-		if !opts.AutoConfirm {
-			// Show update target
-			if onboardingIssue == nil {
-				log.Info("Will create a new onboarding issue with the above content")
-			} else {
-				issueURL := fmt.Sprintf("https://github.com/%s/issues/%d", repoName, onboardingIssue.Number)
-				log.Info("Will update existing onboarding issue (%s) with the above content", issueURL)
-			}
-
-			confirmed, err := prompt.AskForConfirmation("Do you want to proceed with the update?")
-			if err != nil {
-				return err
-			}
-			if !confirmed {
-				log.Info("Update cancelled")
-				return nil
-			}
-		} else {
-			log.Warn("Auto-confirm is enabled, skipping confirmation")
-		}
-
-		// Create or update the onboarding issue
-		if onboardingIssue == nil {
-			// Create new issue
+	// Create or update the onboarding issue
+	target := issueutil.Target{
+		Kind:  "onboarding issue",
+		Title: opts.TargetTitle,
+		Save: func(content string) (*issueutil.Issue, error) {
 			body := map[string]interface{}{
-				"title":  opts.TargetTitle,
-				"body":   content,
-				"labels": []string{opts.TargetLabel},
+				"title": opts.TargetTitle,
+				"body":  content,
+			}
+			if onboardingIssue == nil {
+				body["labels"] = []string{opts.TargetLabel}
 			}
 			bodyBytes, err := json.Marshal(body)
 			if err != nil {
-				return fmt.Errorf("failed to marshal request body: %w", err)
+				return nil, fmt.Errorf("failed to marshal request body: %w", err)
 			}
 
-			path := fmt.Sprintf("repos/%s/issues", repoName)
 			var response struct {
 				Number int `json:"number"`
 			}
-			err = m.client.Post(path, bytes.NewReader(bodyBytes), &response)
-			if err != nil {
-				return fmt.Errorf("failed to create onboarding issue: %w", err)
+			if onboardingIssue == nil {
+				path := fmt.Sprintf("repos/%s/issues", repoName)
+				if err := m.client.Post(path, bytes.NewReader(bodyBytes), &response); err != nil {
+					return nil, fmt.Errorf("failed to create onboarding issue: %w", err)
+				}
+			} else {
+				path := fmt.Sprintf("repos/%s/issues/%d", repoName, onboardingIssue.Number)
+				if err := m.client.Patch(path, bytes.NewReader(bodyBytes), &response); err != nil {
+					return nil, fmt.Errorf("failed to update onboarding issue: %w", err)
+				}
 			}
 			issueURL := fmt.Sprintf("https://github.com/%s/issues/%d", repoName, response.Number)
-			log.Success("Successfully created onboarding issue").
-				L(1).P("→").Log("Onboarding issue URL: %s", issueURL)
-		} else {
-			// Update existing issue
-			body := map[string]interface{}{
-				"title": opts.TargetTitle,
-				"body":  content,
-			}
-			bodyBytes, err := json.Marshal(body)
-			if err != nil {
-				return fmt.Errorf("failed to marshal request body: %w", err)
-			}
-
-			path := fmt.Sprintf("repos/%s/issues/%d", repoName, onboardingIssue.Number)
-			err = m.client.Patch(path, bytes.NewReader(bodyBytes), nil)
-			if err != nil {
-				return fmt.Errorf("failed to update onboarding issue: %w", err)
-			}
-			issueURL := fmt.Sprintf("https://github.com/%s/issues/%d", repoName, onboardingIssue.Number)
-			log.Success("Successfully updated onboarding issue #%d", onboardingIssue.Number).
-				L(1).P("→").Log("Onboarding issue URL: %s", issueURL)
-		}
-	} else {
-		log.Warn("Dry-run mode, skipping update")
+			return &issueutil.Issue{Number: response.Number, Title: opts.TargetTitle, Body: content, URL: issueURL}, nil
+		},
 	}
-
-	return nil
+	if onboardingIssue != nil {
+		issueURL := fmt.Sprintf("https://github.com/%s/issues/%d", repoName, onboardingIssue.Number)
+		target.Existing = &issueutil.Issue{Number: onboardingIssue.Number, Title: onboardingIssue.Title, Body: onboardingIssue.Body, URL: issueURL}
+	}
+	_, err = issueutil.Update(target, content, issueutil.Options{DryRun: opts.DryRun, AutoConfirm: opts.AutoConfirm, Diff: opts.Diff})
+	return err
 }
@@ -3,6 +3,7 @@ package onboard
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/stretchr/testify/assert"
@@ -102,6 +103,13 @@ func TestGenerateContent(t *testing.T) {
 		name    string
 		expects []string
 	}{
+		{
+			name: "custom block",
+			expects: []string{
+				"<!-- CUSTOM:START announcement -->",
+				"<!-- CUSTOM:END -->",
+			},
+		},
 		{
 			name: "header",
 			expects: []string{
@@ -175,3 +183,63 @@ func TestGenerateContent(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "today", formatAge(now, now.Add(-time.Hour)))
+	assert.Equal(t, "1 day ago", formatAge(now, now.AddDate(0, 0, -1)))
+	assert.Equal(t, "12 days ago", formatAge(now, now.AddDate(0, 0, -12)))
+	assert.Equal(t, "1 month ago", formatAge(now, now.AddDate(0, 0, -45)))
+	assert.Equal(t, "3 months ago", formatAge(now, now.AddDate(0, 0, -100)))
+	assert.Equal(t, "1 year ago", formatAge(now, now.AddDate(-1, 0, -1)))
+	assert.Equal(t, "3 years ago", formatAge(now, now.AddDate(-3, 0, -1)))
+	assert.Empty(t, formatAge(time.Time{}, now))
+}
+
+func TestIsStaleClaim(t *testing.T) {
+	staleBefore := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := staleBefore.AddDate(0, 0, -1)
+	recent := staleBefore.AddDate(0, 0, 1)
+	comment := func(login string, at time.Time) timelineEvent {
+		event := timelineEvent{Event: "commented", CreatedAt: at}
+		event.User = &struct {
+			Login string `json:"login"`
+		}{Login: login}
+		return event
+	}
+	issue := OnboardIssue{Status: "open", Assignee: "user1", CreatedAt: old.AddDate(0, 0, -30)}
+
+	assert.True(t, isStaleClaim(issue, []timelineEvent{comment("user1", old)}, staleBefore))
+	assert.False(t, isStaleClaim(issue, []timelineEvent{comment("user1", recent)}, staleBefore))
+
+	// Updates by others, e.g. a label change or a reminder, are not activity of the assignee
+	issue.UpdatedAt = recent
+	assert.True(t, isStaleClaim(issue, []timelineEvent{comment("user1", old), comment("osp-bot", recent)}, staleBefore))
+
+	assert.False(t, isStaleClaim(OnboardIssue{Status: "open", CreatedAt: old}, nil, staleBefore))
+	assert.False(t, isStaleClaim(OnboardIssue{Status: "closed", Assignee: "user1", CreatedAt: old}, nil, staleBefore))
+}
+
+func TestGenerateContentWithDetails(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	issues := []OnboardIssue{
+		{
+			Difficulty: "good first issue",
+			Status:     "open",
+			Assignee:   "user1",
+			Number:     1,
+			Category:   "bug",
+			Title:      "Fix typo in README",
+			CreatedAt:  now.AddDate(0, 0, -100),
+			UpdatedAt:  now.AddDate(0, 0, -40),
+			Comments:   3,
+			StaleClaim: true,
+		},
+	}
+	opts := DefaultOptions()
+	m := &Manager{}
+
+	content, err := m.generateContentWithTime(issues, "elliotxx/osp", opts, "", now)
+	assert.NoError(t, err)
+	assert.Contains(t, content, "- [ ] #1 Fix typo in README **[@user1 's on it! 🚧]** ⏳ *stale claim, feel free to ask to take it over* <sub>💬 3 · opened 3 months ago · updated 1 month ago</sub>\n")
+}
@@ -0,0 +1,141 @@
+package planning
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// velocityWeeks is the number of recent weeks the velocity is averaged over
+	velocityWeeks = 4
+
+	// burndownWeeks is the maximum number of weekly points of the burndown
+	burndownWeeks = 8
+
+	// burndownBarLength is the length of the bar of the largest point of the text burndown
+	burndownBarLength = 20
+)
+
+// BurndownPoint represents the number of open issues of a milestone at a time
+type BurndownPoint struct {
+	Date time.Time
+	Open int
+}
+
+// Forecast represents the forecast completion of a milestone
+type Forecast struct {
+	Velocity       float64    // Issues closed per week over the recent completed weeks
+	Remaining      int        // Open issues
+	CompletionDate *time.Time // nil if there is no recent progress or nothing remains
+	AtRisk         bool       // true if the milestone is forecast to miss its due date
+	Badge          string     // Status badge, e.g. "🟢 On track" or "🔴 At risk"
+}
+
+// endOfWeek returns the last second of the week of the time, weeks running
+// from Monday to Sunday in UTC
+func endOfWeek(t time.Time) time.Time {
+	day := t.UTC().Truncate(24 * time.Hour)
+	daysLeft := (7 - int(day.Weekday())) % 7
+	return day.AddDate(0, 0, daysLeft+1).Add(-time.Second)
+}
+
+// computeBurndown returns the number of open issues at the end of each of the
+// recent weeks up to the current one, skipping weeks before the first issue was
+// created. The points are anchored to week boundaries, so that they only change
+// with the issues within a week. Issues are assumed to belong to the milestone
+// since they were created.
+func computeBurndown(issues []Issue, now time.Time) []BurndownPoint {
+	if len(issues) == 0 {
+		return nil
+	}
+	start := issues[0].CreatedAt
+	for _, issue := range issues {
+		if issue.CreatedAt.Before(start) {
+			start = issue.CreatedAt
+		}
+	}
+
+	end := endOfWeek(now)
+	var points []BurndownPoint
+	for week := burndownWeeks - 1; week >= 0; week-- {
+		date := end.AddDate(0, 0, -7*week)
+		if date.Before(start) && week > 0 {
+			continue
+		}
+		open := 0
+		for _, issue := range issues {
+			if issue.CreatedAt.After(date) {
+				continue
+			}
+			closed := issue.State == "closed" && (issue.ClosedAt == nil || !issue.ClosedAt.After(date))
+			if !closed {
+				open++
+			}
+		}
+		points = append(points, BurndownPoint{Date: date, Open: open})
+	}
+	return points
+}
+
+// computeForecast extrapolates the velocity of the milestone over the recent
+// completed weeks to forecast its completion date, and compares it with the due date
+func computeForecast(issues []Issue, dueOn *time.Time, now time.Time) Forecast {
+	var forecast Forecast
+	until := endOfWeek(now).AddDate(0, 0, -7)
+	since := until.AddDate(0, 0, -7*velocityWeeks)
+	closedRecently := 0
+	for _, issue := range issues {
+		if issue.State != "closed" {
+			forecast.Remaining++
+			continue
+		}
+		if issue.ClosedAt != nil && issue.ClosedAt.After(since) && !issue.ClosedAt.After(until) {
+			closedRecently++
+		}
+	}
+	forecast.Velocity = float64(closedRecently) / velocityWeeks
+
+	if forecast.Remaining == 0 {
+		forecast.Badge = "✅ Completed"
+		return forecast
+	}
+	if forecast.Velocity > 0 {
+		weeks := float64(forecast.Remaining) / forecast.Velocity
+		date := now.Add(time.Duration(weeks * 7 * 24 * float64(time.Hour)))
+		forecast.CompletionDate = &date
+	}
+
+	switch {
+	case dueOn == nil:
+		forecast.Badge = "⚪ No due date"
+	case forecast.CompletionDate == nil || forecast.CompletionDate.After(*dueOn):
+		forecast.AtRisk = true
+		forecast.Badge = "🔴 At risk"
+	default:
+		forecast.Badge = "🟢 On track"
+	}
+	return forecast
+}
+
+// renderBurndown renders the burndown as lines of bars scaled to the largest point
+func renderBurndown(points []BurndownPoint) string {
+	maxOpen := 0
+	for _, point := range points {
+		maxOpen = max(maxOpen, point.Open)
+	}
+
+	lines := make([]string, 0, len(points))
+	for _, point := range points {
+		length := 0
+		if maxOpen > 0 {
+			length = point.Open * burndownBarLength / maxOpen
+		}
+		if point.Open > 0 {
+			length = max(length, 1)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s%s %d",
+			point.Date.Format("2006-01-02"), strings.Repeat("█", length), strings.Repeat("░", burndownBarLength-length), point.Open))
+	}
+	return strings.Join(lines, "\n")
+}
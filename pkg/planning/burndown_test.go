@@ -0,0 +1,141 @@
+package planning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTimedIssue(created time.Time, closed *time.Time) Issue {
+	issue := Issue{State: "open", CreatedAt: created}
+	if closed != nil {
+		issue.State = "closed"
+		issue.ClosedAt = closed
+	}
+	return issue
+}
+
+func TestEndOfWeek(t *testing.T) {
+	sunday := time.Date(2025, 3, 2, 23, 59, 59, 0, time.UTC)
+	assert.Equal(t, sunday, endOfWeek(time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, sunday, endOfWeek(time.Date(2025, 2, 27, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, sunday, endOfWeek(sunday))
+	assert.Equal(t, sunday, endOfWeek(time.Date(2025, 3, 3, 7, 0, 0, 0, time.FixedZone("CST", 8*3600))))
+}
+
+func TestComputeBurndown(t *testing.T) {
+	now := time.Date(2025, 2, 26, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 2, 23, 59, 59, 0, time.UTC)
+	weeksAgo := func(weeks int) time.Time {
+		return end.AddDate(0, 0, -7*weeks)
+	}
+	closed := func(weeks int) *time.Time {
+		t := weeksAgo(weeks).Add(-time.Hour)
+		return &t
+	}
+	closedNow := now.Add(-time.Hour)
+	issues := []Issue{
+		newTimedIssue(weeksAgo(3).Add(-time.Hour), closed(2)),
+		newTimedIssue(weeksAgo(3).Add(-time.Hour), &closedNow),
+		newTimedIssue(weeksAgo(2).Add(-time.Hour), nil),
+		newTimedIssue(weeksAgo(1).Add(-time.Hour), nil),
+	}
+
+	points := computeBurndown(issues, now)
+	assert.Equal(t, []BurndownPoint{
+		{Date: weeksAgo(3), Open: 2},
+		{Date: weeksAgo(2), Open: 2},
+		{Date: weeksAgo(1), Open: 3},
+		{Date: end, Open: 2},
+	}, points)
+	assert.Nil(t, computeBurndown(nil, now))
+
+	// The points stay the same during the week
+	assert.Equal(t, points, computeBurndown(issues, now.AddDate(0, 0, 3)))
+
+	chart := renderBurndown([]BurndownPoint{{Date: weeksAgo(2), Open: 4}, {Date: weeksAgo(1), Open: 1}, {Date: end, Open: 0}})
+	assert.Equal(t, "2025-02-16 ████████████████████ 4\n2025-02-23 █████░░░░░░░░░░░░░░░ 1\n2025-03-02 ░░░░░░░░░░░░░░░░░░░░ 0", chart)
+}
+
+func TestComputeForecast(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	lastWeek := now.AddDate(0, 0, -7)
+	thisWeek := now.AddDate(0, 0, -1)
+	longAgo := now.AddDate(0, 0, -60)
+	issues := []Issue{
+		newTimedIssue(longAgo, &lastWeek),
+		newTimedIssue(longAgo, &lastWeek),
+		newTimedIssue(longAgo, &longAgo),
+		newTimedIssue(longAgo, nil),
+		newTimedIssue(longAgo, nil),
+	}
+
+	// 2 issues closed in the last 4 completed weeks, so 2 remaining issues take 4 weeks
+	due := now.AddDate(0, 0, 35)
+	forecast := computeForecast(issues, &due, now)
+	assert.Equal(t, 0.5, forecast.Velocity)
+	assert.Equal(t, 2, forecast.Remaining)
+	assert.Equal(t, now.AddDate(0, 0, 28), *forecast.CompletionDate)
+	assert.False(t, forecast.AtRisk)
+	assert.Equal(t, "🟢 On track", forecast.Badge)
+
+	due = now.AddDate(0, 0, 14)
+	forecast = computeForecast(issues, &due, now)
+	assert.True(t, forecast.AtRisk)
+	assert.Equal(t, "🔴 At risk", forecast.Badge)
+
+	assert.Equal(t, "⚪ No due date", computeForecast(issues, nil, now).Badge)
+
+	// Issues closed during the current week do not change the velocity until it ends
+	current := append([]Issue{newTimedIssue(longAgo, &thisWeek)}, issues...)
+	assert.Equal(t, 0.5, computeForecast(current, &due, now).Velocity)
+
+	// Without recent progress there is no forecast, which is a risk
+	stalled := computeForecast(issues[2:], &due, now)
+	assert.Nil(t, stalled.CompletionDate)
+	assert.True(t, stalled.AtRisk)
+
+	done := computeForecast(issues[:3], &due, now)
+	assert.False(t, done.AtRisk)
+	assert.Equal(t, "✅ Completed", done.Badge)
+}
+
+func TestGeneratePlanningContentWithForecast(t *testing.T) {
+	due := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	completion := time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC)
+	data := TemplateData{
+		Milestone: Milestone{Title: "v1.0.0", Number: 1, DueOn: &due},
+		Stats: MilestoneStats{
+			TotalIssues:   3,
+			Burndown:      []BurndownPoint{{Date: due, Open: 2}},
+			BurndownChart: "2025-03-15 ████████████████████ 2",
+			Forecast:      Forecast{Velocity: 0.5, Remaining: 2, CompletionDate: &completion, AtRisk: true, Badge: "🔴 At risk"},
+		},
+	}
+	m := &Manager{}
+
+	content, err := m.generatePlanningContentWithTime(data, "", time.Time{})
+	assert.NoError(t, err)
+	assert.Contains(t, content, "- Status: 🔴 At risk (velocity: 0.5 issues/week, forecast: March 29, 2025)\n")
+	assert.Contains(t, content, "## Burndown\n> Open issues at the end of each recent week.\n\n```text\n2025-03-15 ████████████████████ 2\n```\n")
+
+	// The forecast completion date moves daily, which does not change the hash
+	// within the same week
+	marked, err := m.generatePlanningContent(data, "")
+	assert.NoError(t, err)
+	later := completion.AddDate(0, 0, 1)
+	data.Stats.Forecast.CompletionDate = &later
+	other, err := m.generatePlanningContent(data, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, marked, other)
+	assert.True(t, hashutil.Match(marked, other))
+
+	// A forecast moving to another week changes the hash
+	nextWeek := completion.AddDate(0, 0, 7)
+	data.Stats.Forecast.CompletionDate = &nextWeek
+	moved, err := m.generatePlanningContent(data, "")
+	assert.NoError(t, err)
+	assert.False(t, hashutil.Match(marked, moved))
+}
@@ -0,0 +1,175 @@
+package planning
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// linksBatchSize is the number of issues whose links are resolved per GraphQL query
+const linksBatchSize = 50
+
+// taskPattern matches the checkbox items of a Markdown task list
+var taskPattern = regexp.MustCompile(`^\s*[-*+] \[([ xX])\] (.+)$`)
+
+// LinkedPullRequest represents a pull request that closes an issue
+type LinkedPullRequest struct {
+	Number int
+	Title  string
+	URL    string
+	State  string // open, draft, merged or closed
+	Review string // approved, changes requested, review required, or empty if there is no review policy
+}
+
+// SubIssue represents a sub-issue of an issue
+type SubIssue struct {
+	Number int
+	Title  string
+	URL    string
+	State  string // open or closed
+}
+
+// Task represents a checkbox item of the task list in the body of an issue
+type Task struct {
+	Text string
+	Done bool
+}
+
+// linkedIssue represents the links of an issue as returned by GraphQL
+type linkedIssue struct {
+	ClosedByPullRequestsReferences struct {
+		Nodes []struct {
+			Number         int    `json:"number"`
+			Title          string `json:"title"`
+			URL            string `json:"url"`
+			State          string `json:"state"`
+			IsDraft        bool   `json:"isDraft"`
+			ReviewDecision string `json:"reviewDecision"`
+		} `json:"nodes"`
+	} `json:"closedByPullRequestsReferences"`
+	SubIssues struct {
+		Nodes []struct {
+			Number int    `json:"number"`
+			Title  string `json:"title"`
+			URL    string `json:"url"`
+			State  string `json:"state"`
+		} `json:"nodes"`
+	} `json:"subIssues"`
+}
+
+// resolveLinks fills the linked pull requests, sub-issues and tasks of the issues.
+// Links are best effort, so a failure to resolve them is only logged.
+func (m *Manager) resolveLinks(ctx context.Context, owner, repo string, issues []Issue) {
+	var numbers []int
+	for i := range issues {
+		if isPullRequest(issues[i]) {
+			continue
+		}
+		issues[i].Tasks = parseTasks(issues[i].Body)
+		numbers = append(numbers, issues[i].Number)
+	}
+	if m.gqlClient == nil || len(numbers) == 0 {
+		return
+	}
+
+	links, err := m.listLinks(ctx, owner, repo, numbers)
+	if err != nil {
+		log.Warn("Failed to resolve linked pull requests and sub-issues: %v", err)
+		return
+	}
+	for i := range issues {
+		if link, ok := links[issues[i].Number]; ok {
+			issues[i].LinkedPullRequests, issues[i].SubIssues = convertLinks(link)
+		}
+	}
+}
+
+// listLinks returns the links of the issues by number, in batches of GraphQL queries
+func (m *Manager) listLinks(ctx context.Context, owner, repo string, numbers []int) (map[int]linkedIssue, error) {
+	links := make(map[int]linkedIssue)
+	for start := 0; start < len(numbers); start += linksBatchSize {
+		batch := numbers[start:min(start+linksBatchSize, len(numbers))]
+
+		var fields strings.Builder
+		for _, number := range batch {
+			fmt.Fprintf(&fields, `
+    issue%d: issue(number: %d) {
+      closedByPullRequestsReferences(first: 10, includeClosedPrs: true) {
+        nodes { number title url state isDraft reviewDecision }
+      }
+      subIssues(first: 50) {
+        nodes { number title url state }
+      }
+    }`, number, number)
+		}
+		query := fmt.Sprintf(`query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {%s
+  }
+}`, fields.String())
+
+		var response struct {
+			Repository map[string]*linkedIssue `json:"repository"`
+		}
+		variables := map[string]interface{}{"owner": owner, "name": repo}
+		if err := m.gqlClient.DoWithContext(ctx, query, variables, &response); err != nil {
+			return nil, fmt.Errorf("failed to get issue links: %w", err)
+		}
+		for _, number := range batch {
+			if link := response.Repository[fmt.Sprintf("issue%d", number)]; link != nil {
+				links[number] = *link
+			}
+		}
+	}
+	log.Debug("Resolved links of %d issues", len(links))
+	return links, nil
+}
+
+// convertLinks converts the links of an issue returned by GraphQL
+func convertLinks(link linkedIssue) ([]LinkedPullRequest, []SubIssue) {
+	var pullRequests []LinkedPullRequest
+	for _, node := range link.ClosedByPullRequestsReferences.Nodes {
+		state := strings.ToLower(node.State)
+		if state == "open" && node.IsDraft {
+			state = "draft"
+		}
+		pullRequests = append(pullRequests, LinkedPullRequest{
+			Number: node.Number,
+			Title:  node.Title,
+			URL:    node.URL,
+			State:  state,
+			Review: strings.ToLower(strings.ReplaceAll(node.ReviewDecision, "_", " ")),
+		})
+	}
+
+	var subIssues []SubIssue
+	for _, node := range link.SubIssues.Nodes {
+		subIssues = append(subIssues, SubIssue{
+			Number: node.Number,
+			Title:  node.Title,
+			URL:    node.URL,
+			State:  strings.ToLower(node.State),
+		})
+	}
+	return pullRequests, subIssues
+}
+
+// parseTasks returns the checkbox items of the task lists in the body of an issue
+func parseTasks(body string) []Task {
+	var tasks []Task
+	for _, line := range strings.Split(body, "\n") {
+		match := taskPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+		tasks = append(tasks, Task{Text: strings.TrimSpace(match[2]), Done: match[1] != " "})
+	}
+	return tasks
+}
+
+// isPullRequest reports whether the issue is a pull request
+func isPullRequest(issue Issue) bool {
+	return strings.Contains(issue.HTMLURL, "/pull/")
+}
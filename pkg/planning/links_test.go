@@ -0,0 +1,78 @@
+package planning
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTasks(t *testing.T) {
+	body := "## Tasks\r\n- [x] Design the API\r\n- [ ] Implement #12\n  * [X] Nested item\n- Not a task\n- [] Malformed\n"
+	assert.Equal(t, []Task{
+		{Text: "Design the API", Done: true},
+		{Text: "Implement #12", Done: false},
+		{Text: "Nested item", Done: true},
+	}, parseTasks(body))
+	assert.Nil(t, parseTasks("No tasks here"))
+}
+
+func TestConvertLinks(t *testing.T) {
+	var link linkedIssue
+	err := json.Unmarshal([]byte(`{
+  "closedByPullRequestsReferences": {"nodes": [
+    {"number": 10, "state": "OPEN", "isDraft": true},
+    {"number": 11, "state": "OPEN", "reviewDecision": "CHANGES_REQUESTED"},
+    {"number": 12, "state": "MERGED", "reviewDecision": "APPROVED"}
+  ]},
+  "subIssues": {"nodes": [{"number": 20, "state": "CLOSED"}]}
+}`), &link)
+	require.NoError(t, err)
+
+	pullRequests, subIssues := convertLinks(link)
+	assert.Equal(t, []LinkedPullRequest{
+		{Number: 10, State: "draft"},
+		{Number: 11, State: "open", Review: "changes requested"},
+		{Number: 12, State: "merged", Review: "approved"},
+	}, pullRequests)
+	assert.Equal(t, []SubIssue{{Number: 20, State: "closed"}}, subIssues)
+}
+
+func TestResolveLinksWithoutGraphQL(t *testing.T) {
+	issues := []Issue{
+		{Number: 1, HTMLURL: "https://github.com/owner/repo/issues/1", Body: "- [ ] Task"},
+		{Number: 2, HTMLURL: "https://github.com/owner/repo/pull/2", Body: "- [x] Checklist of the pull request"},
+	}
+	m := &Manager{}
+
+	m.resolveLinks(context.Background(), "owner", "repo", issues)
+	assert.Equal(t, []Task{{Text: "Task"}}, issues[0].Tasks)
+	assert.Nil(t, issues[1].Tasks)
+}
+
+func TestGeneratePlanningContentWithLinks(t *testing.T) {
+	data := TemplateData{
+		Milestone: Milestone{Title: "v1.0.0", Number: 1},
+		Stats:     MilestoneStats{TotalIssues: 1},
+		Issues: map[string][]Issue{
+			"enhancement": {{
+				Number: 1,
+				State:  "open",
+				LinkedPullRequests: []LinkedPullRequest{
+					{Number: 10, State: "open", Review: "approved"},
+					{Number: 11, State: "draft"},
+				},
+				SubIssues: []SubIssue{{Number: 2, State: "closed"}, {Number: 3, State: "open"}},
+				Tasks:     []Task{{Text: "Write docs", Done: true}},
+			}},
+		},
+	}
+	m := &Manager{}
+
+	content, err := m.generatePlanningContentWithTime(data, "", time.Time{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "### enhancement (1)\n- [ ]  #1\n  - 🔀 #10 (open, approved)\n  - 🔀 #11 (draft)\n  - [x] #2\n  - [ ] #3\n  - [x] Write docs\n")
+}
@@ -0,0 +1,123 @@
+package planning
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// multiRepoTitlePrefix marks the title of the planning issue of milestones across
+// repositories, which would otherwise be the same as the title of the planning
+// issue of the milestone with the same title in the meta repository itself
+const multiRepoTitlePrefix = "[multi-repo] "
+
+// RepoPlanning represents the milestone of one of the repositories planned together
+type RepoPlanning struct {
+	Repo                string // Repository in owner/name format
+	Milestone           Milestone
+	Stats               MilestoneStats
+	Issues              map[string][]Issue
+	UncategorizedIssues []Issue
+	ProgressBar         string
+}
+
+// UpdateRepos updates or creates a single planning issue in owner/repo for the
+// milestones with the same title across the repositories. Issues are grouped
+// by repository and category, and the statistics are combined. The title of
+// the planning issue is prefixed with "[multi-repo] ".
+func (m *Manager) UpdateRepos(ctx context.Context, owner, repo string, repos []string, milestoneTitle string, opts Options) error {
+	log.Debug("Updating planning issue for milestone '%s' of %d repositories in %s/%s", milestoneTitle, len(repos), owner, repo)
+	opts.TargetTitle = multiRepoTitlePrefix + opts.TargetTitle
+
+	var plannings []RepoPlanning
+	var allIssues []Issue
+	combined := Milestone{
+		Title:   milestoneTitle,
+		State:   "closed",
+		HTMLURL: fmt.Sprintf("https://github.com/%s/%s", owner, repo),
+	}
+	for _, repoName := range repos {
+		repoOwner, name, ok := strings.Cut(repoName, "/")
+		if !ok {
+			return fmt.Errorf("invalid repository format: %s", repoName)
+		}
+
+		milestone, err := m.findMilestone(ctx, repoOwner, name, milestoneTitle)
+		if err != nil {
+			return err
+		}
+		if milestone == nil {
+			log.Warn("No milestone titled '%s' found in %s, skipping", milestoneTitle, repoName)
+			continue
+		}
+		log.Debug("Found milestone: %s (#%d) in %s", milestone.Title, milestone.Number, repoName)
+
+		issues, err := m.ListMilestoneIssues(ctx, repoOwner, name, milestone.Number, opts.ExcludePR)
+		if err != nil {
+			return err
+		}
+		m.resolveLinks(ctx, repoOwner, name, issues)
+
+		data := m.prepareTemplateData(*milestone, issues, opts)
+		plannings = append(plannings, RepoPlanning{
+			Repo:                repoName,
+			Milestone:           *milestone,
+			Stats:               data.Stats,
+			Issues:              data.Issues,
+			UncategorizedIssues: data.UncategorizedIssues,
+			ProgressBar:         data.ProgressBar,
+		})
+		allIssues = append(allIssues, issues...)
+
+		// The combined milestone is due with the latest milestone, and open
+		// while any milestone is open
+		if milestone.DueOn != nil && (combined.DueOn == nil || milestone.DueOn.After(*combined.DueOn)) {
+			combined.DueOn = milestone.DueOn
+		}
+		if milestone.State == "open" {
+			combined.State = "open"
+		}
+		if combined.Description == "" {
+			combined.Description = milestone.Description
+		}
+	}
+	if len(plannings) == 0 {
+		return fmt.Errorf("no milestone titled '%s' found in %s", milestoneTitle, strings.Join(repos, ", "))
+	}
+
+	data := m.prepareTemplateData(combined, allIssues, opts)
+	data.Repos = plannings
+
+	return m.updatePlanning(owner, repo, data, opts)
+}
+
+// findMilestone returns the milestone of the repository with the title, or nil if there is none
+func (m *Manager) findMilestone(ctx context.Context, owner, repo, title string) (*Milestone, error) {
+	milestones, err := m.listMilestones(owner, repo, "all")
+	if err != nil {
+		return nil, err
+	}
+	for i := range milestones {
+		if milestones[i].Title == title {
+			return &milestones[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// issueRef returns the reference of an issue or pull request as written in the
+// planning issue of owner/repo: "#123" in the same repository, "org/other#123"
+// in another one
+func issueRef(issueURL string, number int, owner, repo string) string {
+	u, err := url.Parse(issueURL)
+	if err == nil {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 2 && !(strings.EqualFold(parts[0], owner) && strings.EqualFold(parts[1], repo)) {
+			return fmt.Sprintf("%s/%s#%d", parts[0], parts[1], number)
+		}
+	}
+	return fmt.Sprintf("#%d", number)
+}
@@ -0,0 +1,119 @@
+package planning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueRef(t *testing.T) {
+	assert.Equal(t, "#12", issueRef("https://github.com/org/meta/issues/12", 12, "org", "meta"))
+	assert.Equal(t, "#12", issueRef("https://github.com/Org/Meta/pull/12", 12, "org", "meta"))
+	assert.Equal(t, "org/a#12", issueRef("https://github.com/org/a/issues/12", 12, "org", "meta"))
+	assert.Equal(t, "#12", issueRef("", 12, "org", "meta"))
+}
+
+func TestGeneratePlanningContentForRepos(t *testing.T) {
+	data := TemplateData{
+		Milestone: Milestone{Title: "v1.2"},
+		Stats:     MilestoneStats{TotalIssues: 3, CompletedIssues: 1},
+		RepoOwner: "org",
+		RepoName:  "meta",
+		Repos: []RepoPlanning{
+			{
+				Repo:        "org/a",
+				Milestone:   Milestone{Number: 3, HTMLURL: "https://github.com/org/a/milestone/3"},
+				Stats:       MilestoneStats{TotalIssues: 2},
+				ProgressBar: "50%",
+				Issues: map[string][]Issue{
+					"bug": {{Number: 1, State: "closed", HTMLURL: "https://github.com/org/a/issues/1"}},
+				},
+				UncategorizedIssues: []Issue{{
+					Number:             2,
+					State:              "open",
+					HTMLURL:            "https://github.com/org/a/issues/2",
+					LinkedPullRequests: []LinkedPullRequest{{Number: 4, URL: "https://github.com/org/a/pull/4", State: "open"}},
+				}},
+			},
+			{
+				Repo:        "org/b",
+				Milestone:   Milestone{Number: 5, HTMLURL: "https://github.com/org/b/milestone/5"},
+				Stats:       MilestoneStats{TotalIssues: 1},
+				ProgressBar: "0%",
+				Issues: map[string][]Issue{
+					"enhancement": {{Number: 1, State: "open", HTMLURL: "https://github.com/org/b/issues/1"}},
+				},
+			},
+		},
+	}
+	m := &Manager{}
+
+	content, err := m.generatePlanningContentWithTime(data, "", time.Time{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "- [Total Issues: 3](https://github.com/search?type=issues&q=is%3Aissue+milestone%3Av1.2+repo%3Aorg/a+repo%3Aorg/b)\n")
+	assert.Contains(t, content, "- Data comes from milestones [org/a#3](https://github.com/org/a/milestone/3), [org/b#5](https://github.com/org/b/milestone/5)\n")
+	assert.Contains(t, content, "\n## org/a (2)\n- Progress: 50%\n\n### bug (1)\n- [x]  org/a#1\n\n### Uncategorized (1)\n- [ ]  org/a#2\n  - 🔀 org/a#4 (open)\n")
+	assert.Contains(t, content, "\n## org/b (1)\n- Progress: 0%\n\n### enhancement (1)\n- [ ]  org/b#1\n")
+	assert.Contains(t, content, "- 📊 [All org/b milestone issues](https://github.com/org/b/milestone/5)\n")
+	assert.NotContains(t, content, "## Tasks by Category")
+}
+
+func TestFindMilestone(t *testing.T) {
+	// 101 milestones take two pages, v1.2 is on the second one
+	var milestones []Milestone
+	for number := 1; number <= 100; number++ {
+		milestones = append(milestones, Milestone{Number: number, Title: fmt.Sprintf("v0.%d", number)})
+	}
+	milestones = append(milestones, Milestone{Number: 101, Title: "v1.2"})
+
+	m := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/org/a/milestones", r.URL.Path)
+		assert.Equal(t, "all", r.URL.Query().Get("state"))
+		page := milestones[:100]
+		if r.URL.Query().Get("page") == "2" {
+			page = milestones[100:]
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	milestone, err := m.findMilestone(context.Background(), "org", "a", "v1.2")
+	require.NoError(t, err)
+	require.NotNil(t, milestone)
+	assert.Equal(t, 101, milestone.Number)
+
+	milestone, err = m.findMilestone(context.Background(), "org", "a", "v2.0")
+	require.NoError(t, err)
+	assert.Nil(t, milestone)
+}
+
+func TestUpdateReposTitle(t *testing.T) {
+	var created map[string]interface{}
+	m := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/org/a/milestones":
+			_, _ = w.Write([]byte(`[{"number": 3, "title": "v1.2", "state": "open", "html_url": "https://github.com/org/a/milestone/3"}]`))
+		case r.URL.Path == "/repos/org/a/issues":
+			_, _ = w.Write([]byte(`[]`))
+		case r.URL.Path == "/repos/org/meta/issues" && r.Method == http.MethodGet:
+			// The planning issue of the milestone v1.2 of the meta repository itself
+			_, _ = w.Write([]byte(`[{"number": 1, "title": "Planning: v1.2", "state": "open", "body": "own planning"}]`))
+		case r.URL.Path == "/repos/org/meta/issues" && r.Method == http.MethodPost:
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			_, _ = w.Write([]byte(`{"number": 9}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	opts := DefaultOptions()
+	opts.AutoConfirm = true
+	err := m.UpdateRepos(context.Background(), "org", "meta", []string{"org/a"}, "v1.2", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "[multi-repo] Planning: v1.2", created["title"])
+}
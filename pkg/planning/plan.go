@@ -15,7 +15,9 @@ import (
 
 	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/elliotxx/osp/pkg/log"
-	"github.com/elliotxx/osp/pkg/util/prompt"
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/elliotxx/osp/pkg/util/issueutil"
+	"github.com/elliotxx/osp/pkg/util/tmplutil"
 )
 
 //go:embed templates/planning.gotmpl
@@ -23,24 +25,35 @@ var templates embed.FS
 
 // Manager handles GitHub planning
 type Manager struct {
-	client *api.RESTClient
+	client    *api.RESTClient
+	gqlClient *api.GraphQLClient
 }
 
-// NewManager creates a new plan manager
-func NewManager(client *api.RESTClient) *Manager {
+// NewManager creates a new plan manager. The GraphQL client is only used for
+// GitHub Projects and may be nil otherwise.
+func NewManager(client *api.RESTClient, gqlClient *api.GraphQLClient) *Manager {
 	return &Manager{
-		client: client,
+		client:    client,
+		gqlClient: gqlClient,
 	}
 }
 
 // Issue represents a GitHub issue
 type Issue struct {
-	Title    string  `json:"title"`
-	Number   int     `json:"number"`
-	State    string  `json:"state"`
-	Labels   []Label `json:"labels"`
-	Assignee *User   `json:"assignee"`
-	HTMLURL  string  `json:"html_url"`
+	Title     string     `json:"title"`
+	Number    int        `json:"number"`
+	State     string     `json:"state"`
+	Labels    []Label    `json:"labels"`
+	Assignee  *User      `json:"assignee"`
+	HTMLURL   string     `json:"html_url"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+
+	// Resolved separately from the issue
+	LinkedPullRequests []LinkedPullRequest `json:"-"`
+	SubIssues          []SubIssue          `json:"-"`
+	Tasks              []Task              `json:"-"`
 }
 
 // Label represents a GitHub label
@@ -61,29 +74,37 @@ type Milestone struct {
 	Number      int        `json:"number"`
 	State       string     `json:"state"`
 	HTMLURL     string     `json:"html_url"`
+	ClosedAt    *time.Time `json:"closed_at"`
 }
 
 // Options represents planning options
 type Options struct {
-	PlanningLabel string   // Label used to locate the issue where planning content will be updated
-	TargetTitle   string   // Title template of the target issue where planning content will be updated
-	Categories    []string // Labels used to classify issues by type
-	Priorities    []string // Labels used to indicate issue priority, ordered from high to low
-	ExcludePR     bool     // If true, exclude pull requests from planning content
-	DryRun        bool     // If true, only show preview without making changes
-	AutoConfirm   bool     // If true, skip confirmation prompt
+	PlanningLabel   string   // Label used to locate the issue where planning content will be updated
+	TargetTitle     string   // Title template of the target issue where planning content will be updated
+	Categories      []string // Labels used to classify issues by type
+	Priorities      []string // Labels used to indicate issue priority, ordered from high to low
+	MaxHighPriority int      // Maximum number of open high-priority issues per assignee before they are highlighted as overloaded
+	ExcludePR       bool     // If true, exclude pull requests from planning content
+	Template        string   // Path of a custom planning template, on local disk or inside the repository
+	Diff            bool     // If true, only show the changes and return diff.ErrChanges if there are any
+	DryRun          bool     // If true, only show preview without making changes
+	AutoConfirm     bool     // If true, skip confirmation prompt
+	CarryOver       bool     // If true, move the issues left open in a closed milestone to the next milestone
 }
 
 // DefaultOptions returns default planning options
 func DefaultOptions() Options {
 	return Options{
-		PlanningLabel: "planning",
-		TargetTitle:   "Planning: {{ .Title }}",
-		Categories:    []string{"bug", "enhancement", "documentation"},
-		Priorities:    []string{"priority/high", "priority/medium", "priority/low"},
-		ExcludePR:     true,
-		DryRun:        false,
-		AutoConfirm:   false,
+		PlanningLabel:   "planning",
+		TargetTitle:     "Planning: {{ .Title }}",
+		Categories:      []string{"bug", "enhancement", "documentation"},
+		Priorities:      []string{"priority/high", "priority/medium", "priority/low"},
+		MaxHighPriority: 3,
+		ExcludePR:       true,
+		Diff:            false,
+		DryRun:          false,
+		AutoConfirm:     false,
+		CarryOver:       false,
 	}
 }
 
@@ -93,6 +114,10 @@ type MilestoneStats struct {
 	CompletedIssues int
 	Progress        float64
 	Contributors    []string
+	Burndown        []BurndownPoint // Open issues at the end of each recent week
+	BurndownChart   string          // Text rendering of the burndown
+	Forecast        Forecast
+	Workloads       []Workload // Issues held by each assignee
 }
 
 // TemplateData represents the data passed to the template
@@ -107,6 +132,12 @@ type TemplateData struct {
 	Priorities          []string
 	RepoOwner           string
 	RepoName            string
+	UnassignedIssues    []Issue // Open issues without assignee
+	UnprioritizedIssues []Issue // Open issues without priority label
+	MaxHighPriority     int
+	Project             *Project       // Set if the issues come from a project instead of a milestone
+	Retrospective       *Retrospective // Set if the milestone is closed, to write its final report
+	Repos               []RepoPlanning // Set if the milestones of several repositories are planned together
 }
 
 // Update updates or creates a planning issue for a milestone
@@ -122,166 +153,187 @@ func (m *Manager) Update(ctx context.Context, owner, repo string, milestoneNumbe
 	}
 	log.Debug("Found milestone: %s (#%d)", milestone.Title, milestone.Number)
 
-	// Get all issues in the milestone with pagination
-	var allIssues []Issue
-	page := 1
-	for {
-		var issues []Issue
-		path = fmt.Sprintf("repos/%s/%s/issues?milestone=%d&state=all&page=%d&per_page=100", owner, repo, milestoneNumber, page)
-		err = m.client.Get(path, &issues)
-		if err != nil {
-			return fmt.Errorf("failed to get issues: %w", err)
-		}
-
-		allIssues = append(allIssues, issues...)
-		log.Debug("Got %d issues from page %d", len(issues), page)
-
-		// If we got less than per_page items, we've reached the end
-		if len(issues) < 100 {
-			break
-		}
-		page++
+	// Get all issues in the milestone
+	issues, err := m.ListMilestoneIssues(ctx, owner, repo, milestoneNumber, opts.ExcludePR)
+	if err != nil {
+		return err
 	}
-	issues := allIssues
-	log.Debug("Found total %d issues in milestone", len(issues))
 
-	// Filter out pull requests if exclude_pr is true
-	if opts.ExcludePR {
-		var filtered []Issue
-		for _, issue := range issues {
-			if !strings.Contains(issue.HTMLURL, "/pull/") {
-				filtered = append(filtered, issue)
-			}
-		}
-		issues = filtered
-	}
+	// Resolve linked pull requests, sub-issues and tasks of the issues
+	m.resolveLinks(ctx, owner, repo, issues)
 
 	// Prepare data for template
 	data := m.prepareTemplateData(milestone, issues, opts)
 
+	return m.updatePlanning(owner, repo, data, opts)
+}
+
+// updatePlanning renders the planning content of the template data and
+// creates or updates the planning issue of its milestone. The planning issue
+// is closed along with its retrospective once the milestone is closed.
+func (m *Manager) updatePlanning(owner, repo string, data TemplateData, opts Options) error {
+	milestone := data.Milestone
+	data.RepoOwner, data.RepoName = owner, repo
+
+	// Load custom template if specified
+	var tmplText string
+	var err error
+	if opts.Template != "" {
+		tmplText, err = tmplutil.Load(m.client, fmt.Sprintf("%s/%s", owner, repo), opts.Template)
+		if err != nil {
+			return fmt.Errorf("failed to load planning template: %w", err)
+		}
+	}
+
 	// Generate planning content
-	content, err := m.generatePlanningContent(data)
+	content, err := m.generatePlanningContent(data, tmplText)
 	if err != nil {
 		return fmt.Errorf("failed to generate planning content: %w", err)
 	}
 	log.Debug("Generated planning content with %d bytes", len(content))
 
-	// Find existing planning issues
-	path = fmt.Sprintf("repos/%s/%s/issues?labels=%s&state=all", owner, repo, opts.PlanningLabel)
-	var existingIssues []Issue
-	err = m.client.Get(path, &existingIssues)
+	// Find existing planning issue
+	planningIssue, planningTitle, err := m.findPlanningIssue(owner, repo, milestone, opts)
 	if err != nil {
-		return fmt.Errorf("failed to get existing planning issues: %w", err)
+		return err
 	}
-	log.Debug("Found %d existing issues with planning label", len(existingIssues))
 
-	// Render planning title
-	tmpl, err := template.New("title").Parse(opts.TargetTitle)
-	if err != nil {
-		return fmt.Errorf("failed to parse title template: %w", err)
-	}
-	var titleBuf bytes.Buffer
-	err = tmpl.Execute(&titleBuf, milestone)
-	if err != nil {
-		return fmt.Errorf("failed to execute title template: %w", err)
+	// A retrospective also closes the planning issue
+	closeIssue := data.Retrospective != nil && (planningIssue == nil || planningIssue.State != "closed")
+	if closeIssue {
+		log.Info("Milestone '%s' is closed, the planning issue will be closed with its retrospective", milestone.Title)
 	}
-	planningTitle := titleBuf.String()
 
-	// Find existing planning issue
-	var planningIssue *Issue
-	var minIssueNumber int = math.MaxInt32
-	for _, issue := range existingIssues {
-		if issue.Title == planningTitle {
-			if planningIssue == nil || issue.Number < minIssueNumber {
-				planningIssue = &issue
-				minIssueNumber = issue.Number
-				log.Debug("Found planning issue #%d with title '%s'", issue.Number, issue.Title)
+	// Create or update the planning issue
+	target := issueutil.Target{
+		Kind:  "planning issue",
+		Title: planningTitle,
+		Force: closeIssue,
+		Save: func(content string) (*issueutil.Issue, error) {
+			body := map[string]interface{}{
+				"title": planningTitle,
+				"body":  content,
 			}
-		}
-	}
-
-	// Show preview
-	if planningIssue == nil {
-		log.Info("Creating new planning issue for milestone '%s'", milestone.Title)
-	} else {
-		log.Info("Updating existing planning issue #%d for milestone #%d (%s)", planningIssue.Number, milestone.Number, milestone.Title)
-	}
-
-	// Preview the content
-	log.C(log.ColorBlue).P("↓").Log("Preview of the planning content:")
-	log.C(log.ColorCyan).Log("%s", content)
-
-	if !opts.DryRun {
-		// Ask for confirmation if auto-confirm is not enabled
-		if !opts.AutoConfirm {
-			// Show update target
 			if planningIssue == nil {
-				log.Info("Will create a new planning issue with the above content")
-			} else {
-				issueURL := fmt.Sprintf("https://github.com/%s/%s/issues/%d", owner, repo, planningIssue.Number)
-				log.Info("Will update existing planning issue (%s) with the above content", issueURL)
-			}
-
-			confirmed, err := prompt.AskForConfirmation("Do you want to proceed with the update?")
-			if err != nil {
-				return err
-			}
-			if !confirmed {
-				log.Info("Update cancelled")
-				return nil
-			}
-		} else {
-			log.Warn("Auto-confirm is enabled, skipping confirmation")
-		}
-
-		// Create or update the planning issue
-		if planningIssue == nil {
-			// Create new issue
-			body := map[string]interface{}{
-				"title":  planningTitle,
-				"body":   content,
-				"labels": []string{opts.PlanningLabel},
+				body["labels"] = []string{opts.PlanningLabel}
+			} else if closeIssue {
+				body["state"] = "closed"
 			}
 			bodyBytes, err := json.Marshal(body)
 			if err != nil {
-				return fmt.Errorf("failed to marshal request body: %w", err)
+				return nil, fmt.Errorf("failed to marshal request body: %w", err)
 			}
 
-			path := fmt.Sprintf("repos/%s/%s/issues", owner, repo)
 			var response struct {
 				Number int `json:"number"`
 			}
-			err = m.client.Post(path, bytes.NewReader(bodyBytes), &response)
-			if err != nil {
-				return fmt.Errorf("failed to create planning issue: %w", err)
+			if planningIssue == nil {
+				path := fmt.Sprintf("repos/%s/%s/issues", owner, repo)
+				if err := m.client.Post(path, bytes.NewReader(bodyBytes), &response); err != nil {
+					return nil, fmt.Errorf("failed to create planning issue: %w", err)
+				}
+
+				// Issues cannot be created closed
+				if closeIssue {
+					if err := m.closeIssue(owner, repo, response.Number); err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, planningIssue.Number)
+				if err := m.client.Patch(path, bytes.NewReader(bodyBytes), &response); err != nil {
+					return nil, fmt.Errorf("failed to update planning issue: %w", err)
+				}
 			}
 			issueURL := fmt.Sprintf("https://github.com/%s/%s/issues/%d", owner, repo, response.Number)
-			log.Success("Successfully created planning issue for milestone '%s'", milestone.Title).
-				L(1).P("→").Log("Planning issue URL: %s", issueURL)
-		} else {
-			// Update existing issue
-			body := map[string]interface{}{
-				"title": planningTitle,
-				"body":  content,
-			}
-			bodyBytes, err := json.Marshal(body)
-			if err != nil {
-				return fmt.Errorf("failed to marshal request body: %w", err)
-			}
+			return &issueutil.Issue{Number: response.Number, Title: planningTitle, Body: content, URL: issueURL}, nil
+		},
+	}
+	if planningIssue != nil {
+		issueURL := fmt.Sprintf("https://github.com/%s/%s/issues/%d", owner, repo, planningIssue.Number)
+		target.Existing = &issueutil.Issue{Number: planningIssue.Number, Title: planningIssue.Title, Body: planningIssue.Body, URL: issueURL}
+	}
+	_, err = issueutil.Update(target, content, issueutil.Options{DryRun: opts.DryRun, AutoConfirm: opts.AutoConfirm, Diff: opts.Diff})
+	return err
+}
 
-			path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, planningIssue.Number)
-			err = m.client.Patch(path, bytes.NewReader(bodyBytes), nil)
-			if err != nil {
-				return fmt.Errorf("failed to update planning issue: %w", err)
-			}
-			issueURL := fmt.Sprintf("https://github.com/%s/%s/issues/%d", owner, repo, planningIssue.Number)
-			log.Success("Successfully updated planning issue #%d", planningIssue.Number).
-				L(1).P("→").Log("Planning issue URL: %s", issueURL)
+// findPlanningIssue returns the planning issue of the milestone, or nil if there
+// is none yet, along with its title
+func (m *Manager) findPlanningIssue(owner, repo string, milestone Milestone, opts Options) (*Issue, string, error) {
+	existingIssues, err := m.listPlanningIssues(owner, repo, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	planningTitle, err := renderPlanningTitle(milestone, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return selectPlanningIssue(existingIssues, planningTitle), planningTitle, nil
+}
+
+// listPlanningIssues returns all the issues with the planning label, open or closed
+func (m *Manager) listPlanningIssues(owner, repo string, opts Options) ([]Issue, error) {
+	var allIssues []Issue
+	page := 1
+	for {
+		var issues []Issue
+		path := fmt.Sprintf("repos/%s/%s/issues?labels=%s&state=all&page=%d&per_page=100", owner, repo, url.QueryEscape(opts.PlanningLabel), page)
+		err := m.client.Get(path, &issues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get existing planning issues: %w", err)
 		}
-	} else {
-		log.Warn("Dry-run mode, skipping update")
+		allIssues = append(allIssues, issues...)
+
+		// If we got less than per_page items, we've reached the end
+		if len(issues) < 100 {
+			break
+		}
+		page++
+	}
+	log.Debug("Found %d existing issues with planning label", len(allIssues))
+	return allIssues, nil
+}
+
+// renderPlanningTitle renders the title of the planning issue of the milestone
+func renderPlanningTitle(milestone Milestone, opts Options) (string, error) {
+	tmpl, err := template.New("title").Parse(opts.TargetTitle)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse title template: %w", err)
 	}
+	var titleBuf bytes.Buffer
+	err = tmpl.Execute(&titleBuf, milestone)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute title template: %w", err)
+	}
+	return titleBuf.String(), nil
+}
 
+// selectPlanningIssue returns the oldest issue with the planning title, or nil
+// if there is none
+func selectPlanningIssue(issues []Issue, planningTitle string) *Issue {
+	var planningIssue *Issue
+	minIssueNumber := math.MaxInt32
+	for i, issue := range issues {
+		if issue.Title == planningTitle && issue.Number < minIssueNumber {
+			planningIssue = &issues[i]
+			minIssueNumber = issue.Number
+			log.Debug("Found planning issue #%d with title '%s'", issue.Number, issue.Title)
+		}
+	}
+	return planningIssue
+}
+
+// closeIssue closes the issue
+func (m *Manager) closeIssue(owner, repo string, number int) error {
+	bodyBytes, err := json.Marshal(map[string]interface{}{"state": "closed"})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, number)
+	if err := m.client.Patch(path, bytes.NewReader(bodyBytes), nil); err != nil {
+		return fmt.Errorf("failed to close planning issue: %w", err)
+	}
+	log.Success("Successfully closed planning issue #%d", number)
 	return nil
 }
 
@@ -335,8 +387,8 @@ func (m *Manager) prepareTemplateData(milestone Milestone, issues []Issue, opts
 	// Sort issues in each category by priority
 	for category := range issuesByCategory {
 		sort.Slice(issuesByCategory[category], func(i, j int) bool {
-			iPriority := getPriorityLevel(issuesByCategory[category][i].Labels, opts.Priorities)
-			jPriority := getPriorityLevel(issuesByCategory[category][j].Labels, opts.Priorities)
+			iPriority := GetPriorityLevel(issuesByCategory[category][i].Labels, opts.Priorities)
+			jPriority := GetPriorityLevel(issuesByCategory[category][j].Labels, opts.Priorities)
 			if iPriority != jPriority {
 				return iPriority < jPriority // Lower index means higher priority
 			}
@@ -349,15 +401,15 @@ func (m *Manager) prepareTemplateData(milestone Milestone, issues []Issue, opts
 	var highPriorityIssues []Issue
 	if len(opts.Priorities) >= 2 {
 		for _, issue := range issues {
-			level := getPriorityLevel(issue.Labels, opts.Priorities)
+			level := GetPriorityLevel(issue.Labels, opts.Priorities)
 			if level < 2 { // Only include top 2 priority levels
 				highPriorityIssues = append(highPriorityIssues, issue)
 			}
 		}
 		// Sort high priority issues by priority
 		sort.Slice(highPriorityIssues, func(i, j int) bool {
-			iPriority := getPriorityLevel(highPriorityIssues[i].Labels, opts.Priorities)
-			jPriority := getPriorityLevel(highPriorityIssues[j].Labels, opts.Priorities)
+			iPriority := GetPriorityLevel(highPriorityIssues[i].Labels, opts.Priorities)
+			jPriority := GetPriorityLevel(highPriorityIssues[j].Labels, opts.Priorities)
 			if iPriority != jPriority {
 				return iPriority < jPriority
 			}
@@ -371,23 +423,41 @@ func (m *Manager) prepareTemplateData(milestone Milestone, issues []Issue, opts
 		progress = float64(completedIssues) / float64(totalIssues) * 100
 	}
 
+	// Compute the burndown of the recent weeks and forecast the completion
+	now := time.Now()
+	burndown := computeBurndown(issues, now)
+	stats := MilestoneStats{
+		TotalIssues:     totalIssues,
+		CompletedIssues: completedIssues,
+		Progress:        progress,
+		Contributors:    contributorsList,
+		Burndown:        burndown,
+		BurndownChart:   renderBurndown(burndown),
+		Forecast:        computeForecast(issues, milestone.DueOn, now),
+		Workloads:       computeWorkloads(issues, opts.Priorities, opts.MaxHighPriority),
+	}
+	unassignedIssues, unprioritizedIssues := findUnownedIssues(issues, opts.Priorities)
+
 	return TemplateData{
 		Milestone:           milestone,
-		Stats:               MilestoneStats{TotalIssues: totalIssues, CompletedIssues: completedIssues, Progress: progress, Contributors: contributorsList},
+		Stats:               stats,
 		Categories:          opts.Categories,
 		Issues:              issuesByCategory,
 		UncategorizedIssues: uncategorizedIssues,
 		HighPriorityIssues:  highPriorityIssues,
-		ProgressBar:         generateProgressBar(completedIssues, totalIssues, 20),
+		ProgressBar:         GenerateProgressBar(completedIssues, totalIssues, 20),
 		Priorities:          opts.Priorities,
 		RepoOwner:           repoOwner,
 		RepoName:            repoName,
+		UnassignedIssues:    unassignedIssues,
+		UnprioritizedIssues: unprioritizedIssues,
+		MaxHighPriority:     opts.MaxHighPriority,
 	}
 }
 
-// getPriorityLevel returns the priority level of an issue based on its labels
+// GetPriorityLevel returns the priority level of an issue based on its labels
 // Returns the index of the highest priority label found, or len(priorities) if no priority label is found
-func getPriorityLevel(labels []Label, priorities []string) int {
+func GetPriorityLevel(labels []Label, priorities []string) int {
 	for _, label := range labels {
 		for i, priority := range priorities {
 			if strings.EqualFold(label.Name, priority) {
@@ -398,6 +468,44 @@ func getPriorityLevel(labels []Label, priorities []string) int {
 	return len(priorities)
 }
 
+// ListMilestoneIssues returns all issues in the milestone, optionally without pull requests
+func (m *Manager) ListMilestoneIssues(ctx context.Context, owner, repo string, milestoneNumber int, excludePR bool) ([]Issue, error) {
+	// Get all issues in the milestone with pagination
+	var allIssues []Issue
+	page := 1
+	for {
+		var issues []Issue
+		path := fmt.Sprintf("repos/%s/%s/issues?milestone=%d&state=all&page=%d&per_page=100", owner, repo, milestoneNumber, page)
+		err := m.client.Get(path, &issues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get issues: %w", err)
+		}
+
+		allIssues = append(allIssues, issues...)
+		log.Debug("Got %d issues from page %d", len(issues), page)
+
+		// If we got less than per_page items, we've reached the end
+		if len(issues) < 100 {
+			break
+		}
+		page++
+	}
+	log.Debug("Found total %d issues in milestone", len(allIssues))
+
+	// Filter out pull requests if exclude_pr is true
+	if excludePR {
+		var filtered []Issue
+		for _, issue := range allIssues {
+			if !strings.Contains(issue.HTMLURL, "/pull/") {
+				filtered = append(filtered, issue)
+			}
+		}
+		return filtered, nil
+	}
+
+	return allIssues, nil
+}
+
 // ListOpenMilestones returns a list of open milestones for the repository
 func (m *Manager) ListOpenMilestones(ctx context.Context, owner, repo string) ([]Milestone, error) {
 	var milestones []Milestone
@@ -411,13 +519,53 @@ func (m *Manager) ListOpenMilestones(ctx context.Context, owner, repo string) ([
 	return milestones, nil
 }
 
-// generatePlanningContent generates the complete planning content using the template
-func (m *Manager) generatePlanningContent(data TemplateData) (string, error) {
-	return m.generatePlanningContentWithTime(data, time.Now())
+// listMilestones returns the milestones of the repository in the state, the
+// ones due last first
+func (m *Manager) listMilestones(owner, repo, state string) ([]Milestone, error) {
+	var allMilestones []Milestone
+	for page := 1; ; page++ {
+		var milestones []Milestone
+		path := fmt.Sprintf("repos/%s/%s/milestones?state=%s&sort=due_on&direction=desc&page=%d&per_page=100", owner, repo, state, page)
+		if err := m.client.Get(path, &milestones); err != nil {
+			return nil, fmt.Errorf("failed to list milestones of %s/%s: %w", owner, repo, err)
+		}
+		allMilestones = append(allMilestones, milestones...)
+
+		// If we got less than per_page items, we've reached the end
+		if len(milestones) < 100 {
+			break
+		}
+	}
+	return allMilestones, nil
+}
+
+// generatePlanningContent generates the complete planning content using the template.
+// If tmplText is empty, the built-in template is used. The content carries a hidden
+// hash marker, which is computed over the content rendered at a fixed time so that
+// it only changes when the planning itself changes.
+func (m *Manager) generatePlanningContent(data TemplateData, tmplText string) (string, error) {
+	content, err := m.generatePlanningContentWithTime(data, tmplText, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	// The forecast completion date moves with the current time like now, so only
+	// its week is part of the hash. A forecast moving to another week, e.g. when
+	// the velocity drops, still updates the planning issue.
+	stableData := data
+	if date := data.Stats.Forecast.CompletionDate; date != nil {
+		week := endOfWeek(*date)
+		stableData.Stats.Forecast.CompletionDate = &week
+	}
+	stable, err := m.generatePlanningContentWithTime(stableData, tmplText, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	return hashutil.Mark(content, hashutil.Sum(stable)), nil
 }
 
 // generatePlanningContentWithTime generates the complete planning content using the template with a fixed time
-func (m *Manager) generatePlanningContentWithTime(data TemplateData, now time.Time) (string, error) {
+func (m *Manager) generatePlanningContentWithTime(data TemplateData, tmplText string, now time.Time) (string, error) {
 	// Define template functions
 	funcMap := template.FuncMap{
 		"now": func() string {
@@ -433,6 +581,9 @@ func (m *Manager) generatePlanningContentWithTime(data TemplateData, now time.Ti
 			return a - b
 		},
 		"urlEncode": url.QueryEscape,
+		"issueRef": func(issueURL string, number int) string {
+			return issueRef(issueURL, number, data.RepoOwner, data.RepoName)
+		},
 		"getPriorityLevel": func(labels []Label) int {
 			for _, label := range labels {
 				for i, priority := range data.Priorities {
@@ -461,11 +612,22 @@ func (m *Manager) generatePlanningContentWithTime(data TemplateData, now time.Ti
 	}
 
 	// Load template with functions
-	tmpl, err := template.New("planning.gotmpl").Funcs(funcMap).ParseFS(templates, "templates/planning.gotmpl")
+	tmpl := template.New("planning.gotmpl").Funcs(funcMap)
+	var err error
+	if tmplText == "" {
+		tmpl, err = tmpl.ParseFS(templates, "templates/planning.gotmpl")
+	} else {
+		tmpl, err = tmpl.Parse(tmplText)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
+	// Check the template against the template data before rendering
+	if err := tmplutil.Validate(tmpl, data); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
 	// Execute template
 	var buf bytes.Buffer
 	err = tmpl.Execute(&buf, data)
@@ -476,8 +638,8 @@ func (m *Manager) generatePlanningContentWithTime(data TemplateData, now time.Ti
 	return buf.String(), nil
 }
 
-// generateProgressBar generates a progress bar string
-func generateProgressBar(completed int, total int, length int) string {
+// GenerateProgressBar generates a progress bar string
+func GenerateProgressBar(completed int, total int, length int) string {
 	if total == 0 {
 		return strings.Repeat("░", length) + " 0%"
 	}
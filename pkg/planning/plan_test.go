@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/elliotxx/osp/pkg/util/hashutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,7 +42,7 @@ func TestGenerateProgressBar(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := generateProgressBar(tt.completed, tt.total, tt.length)
+			got := GenerateProgressBar(tt.completed, tt.total, tt.length)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -257,7 +258,7 @@ func TestGeneratePlanningContent(t *testing.T) {
 	m := &Manager{}
 
 	// Generate content
-	content, err := m.generatePlanningContentWithTime(data, fixedTime)
+	content, err := m.generatePlanningContentWithTime(data, "", fixedTime)
 
 	// Assertions
 	t.Run("content generation", func(t *testing.T) {
@@ -268,6 +269,10 @@ func TestGeneratePlanningContent(t *testing.T) {
 			name     string
 			expected []string
 		}{
+			{"custom block", []string{
+				"<!-- CUSTOM:START announcement -->",
+				"<!-- CUSTOM:END -->",
+			}},
 			{"header", []string{
 				"## Overview",
 				"- Progress: █████░░░░░░░░░ 33%",
@@ -330,3 +335,48 @@ func TestGeneratePlanningContent(t *testing.T) {
 		}
 	})
 }
+
+func TestGeneratePlanningContentWithCustomTemplate(t *testing.T) {
+	fixedTime := time.Date(2025, 1, 30, 15, 0o4, 0o5, 0, time.UTC)
+	data := TemplateData{
+		Milestone:  Milestone{Title: "v1.0.0", Number: 1},
+		Stats:      MilestoneStats{TotalIssues: 2, CompletedIssues: 1},
+		Priorities: []string{"priority/high", "priority/low"},
+	}
+	m := &Manager{}
+
+	t.Run("built-in functions are available", func(t *testing.T) {
+		content, err := m.generatePlanningContentWithTime(data, "# {{ .Milestone.Title }} {{ getPriorityMark 0 }} {{ urlEncode \"a b\" }} {{ now }}", fixedTime)
+		assert.NoError(t, err)
+		assert.Equal(t, "# v1.0.0 !! a+b January 30, 2025 15:04 UTC", content)
+	})
+
+	t.Run("unknown field is rejected", func(t *testing.T) {
+		_, err := m.generatePlanningContentWithTime(data, "{{ if .Stats.TotalIssues }}{{ else }}{{ .Milestone.Name }}{{ end }}", fixedTime)
+		assert.ErrorContains(t, err, "can't evaluate field Name in type planning.Milestone")
+	})
+}
+
+func TestGeneratePlanningContentHash(t *testing.T) {
+	data := TemplateData{
+		Milestone: Milestone{Title: "v1.0.0", Number: 1},
+		Stats:     MilestoneStats{TotalIssues: 1},
+	}
+	m := &Manager{}
+	tmplText := "{{ .Milestone.Title }} {{ .Stats.TotalIssues }}\n> Last Updated: {{ now }}"
+
+	first, err := m.generatePlanningContent(data, tmplText)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hashutil.Extract(first))
+
+	// The hash is computed over the content rendered at a fixed time
+	stable, err := m.generatePlanningContentWithTime(data, tmplText, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, hashutil.Sum(stable), hashutil.Extract(first))
+
+	// The hash changes with the content
+	data.Stats.TotalIssues = 2
+	third, err := m.generatePlanningContent(data, tmplText)
+	assert.NoError(t, err)
+	assert.False(t, hashutil.Match(first, third))
+}
@@ -0,0 +1,236 @@
+package planning
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// maxProjectItemPages is the maximum number of pages of project items listed
+const maxProjectItemPages = 20
+
+// Project represents a GitHub Projects (v2) board whose items are grouped by a field
+type Project struct {
+	Title  string
+	Number int
+	URL    string
+	Field  string // Name of the single-select or iteration field the items are grouped by
+}
+
+// ProjectGroup represents the items of a project sharing a value of the field
+type ProjectGroup struct {
+	Value  string     // Option name or iteration title
+	DueOn  *time.Time // End of the iteration, nil for single-select fields
+	Issues []Issue
+}
+
+// projectItem represents an item of a project as returned by GraphQL
+type projectItem struct {
+	FieldValue *struct {
+		Name      string `json:"name"`      // single-select option
+		Title     string `json:"title"`     // iteration
+		StartDate string `json:"startDate"` // iteration, in YYYY-MM-DD format
+		Duration  int    `json:"duration"`  // iteration, in days
+	} `json:"fieldValueByName"`
+	Content *struct {
+		Title      string     `json:"title"`
+		Number     int        `json:"number"`
+		State      string     `json:"state"`
+		URL        string     `json:"url"`
+		Body       string     `json:"body"`
+		CreatedAt  time.Time  `json:"createdAt"`
+		ClosedAt   *time.Time `json:"closedAt"`
+		Repository struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"repository"`
+		Labels struct {
+			Nodes []Label `json:"nodes"`
+		} `json:"labels"`
+		Assignees struct {
+			Nodes []User `json:"nodes"`
+		} `json:"assignees"`
+	} `json:"content"`
+}
+
+// ListProjectGroups returns the project owned by the owner of the repository,
+// with the issues and pull requests of the repository grouped by the value of
+// the field. Groups of iteration fields are ordered by start date, and groups
+// of single-select fields by their first appearance.
+func (m *Manager) ListProjectGroups(ctx context.Context, owner, repo string, number int, field string, excludePR bool) (*Project, []ProjectGroup, error) {
+	if m.gqlClient == nil {
+		return nil, nil, fmt.Errorf("GitHub GraphQL client is required for projects")
+	}
+
+	query := `query($owner: String!, $number: Int!, $field: String!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        title number url
+        items(first: 100, after: $cursor) {
+          pageInfo { hasNextPage endCursor }
+          nodes {
+            fieldValueByName(name: $field) {
+              ... on ProjectV2ItemFieldSingleSelectValue { name }
+              ... on ProjectV2ItemFieldIterationValue { title startDate duration }
+            }
+            content {
+              ... on Issue {
+                title number state url body createdAt closedAt
+                repository { nameWithOwner }
+                labels(first: 20) { nodes { name } }
+                assignees(first: 1) { nodes { login } }
+              }
+              ... on PullRequest {
+                title number state url body createdAt closedAt
+                repository { nameWithOwner }
+                labels(first: 20) { nodes { name } }
+                assignees(first: 1) { nodes { login } }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+	var project *Project
+	var items []projectItem
+	var cursor *string
+	for page := 1; page <= maxProjectItemPages; page++ {
+		var response struct {
+			RepositoryOwner struct {
+				ProjectV2 *struct {
+					Title  string `json:"title"`
+					Number int    `json:"number"`
+					URL    string `json:"url"`
+					Items  struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []projectItem `json:"nodes"`
+					} `json:"items"`
+				} `json:"projectV2"`
+			} `json:"repositoryOwner"`
+		}
+		variables := map[string]interface{}{"owner": owner, "number": number, "field": field, "cursor": cursor}
+		if err := m.gqlClient.DoWithContext(ctx, query, variables, &response); err != nil {
+			return nil, nil, fmt.Errorf("failed to get project #%d: %w", number, err)
+		}
+		p := response.RepositoryOwner.ProjectV2
+		if p == nil {
+			return nil, nil, fmt.Errorf("project #%d not found for %s", number, owner)
+		}
+		project = &Project{Title: p.Title, Number: p.Number, URL: p.URL, Field: field}
+		items = append(items, p.Items.Nodes...)
+		log.Debug("Got %d project items from page %d", len(p.Items.Nodes), page)
+
+		if !p.Items.PageInfo.HasNextPage {
+			break
+		}
+		if page == maxProjectItemPages {
+			log.Warn("Only the first %d project items are included", len(items))
+		}
+		endCursor := p.Items.PageInfo.EndCursor
+		cursor = &endCursor
+	}
+
+	groups := groupProjectItems(items, fmt.Sprintf("%s/%s", owner, repo), excludePR)
+	log.Debug("Found %d groups of field '%s' in project '%s'", len(groups), field, project.Title)
+	return project, groups, nil
+}
+
+// UpdateProject updates or creates the planning issue of a group of project items
+func (m *Manager) UpdateProject(ctx context.Context, owner, repo string, project *Project, group ProjectGroup, opts Options) error {
+	log.Debug("Updating planning issue for %s '%s' of project #%d in %s/%s", project.Field, group.Value, project.Number, owner, repo)
+
+	// Fill the milestone from the group, so that the planning looks the same as
+	// the one of a milestone
+	milestone := Milestone{
+		Title:       group.Value,
+		DueOn:       group.DueOn,
+		Description: fmt.Sprintf("Items of project [%s](%s) with %s '%s'.", project.Title, project.URL, project.Field, group.Value),
+		Number:      project.Number,
+		State:       "open",
+		HTMLURL:     project.URL,
+	}
+
+	m.resolveLinks(ctx, owner, repo, group.Issues)
+	data := m.prepareTemplateData(milestone, group.Issues, opts)
+	data.Project = project
+
+	return m.updatePlanning(owner, repo, data, opts)
+}
+
+// groupProjectItems groups the issues of the repository by their field value,
+// skipping items without a value, draft issues and items of other repositories
+func groupProjectItems(items []projectItem, repoName string, excludePR bool) []ProjectGroup {
+	var groups []ProjectGroup
+	index := make(map[string]int)
+	starts := make(map[string]time.Time)
+	for _, item := range items {
+		if item.Content == nil || item.Content.Number == 0 || item.FieldValue == nil {
+			continue
+		}
+		if !strings.EqualFold(item.Content.Repository.NameWithOwner, repoName) {
+			continue
+		}
+		if excludePR && strings.Contains(item.Content.URL, "/pull/") {
+			continue
+		}
+
+		value := item.FieldValue.Name
+		var dueOn *time.Time
+		if item.FieldValue.Title != "" {
+			value = item.FieldValue.Title
+			if start, err := time.Parse("2006-01-02", item.FieldValue.StartDate); err == nil {
+				starts[value] = start
+				end := start.AddDate(0, 0, item.FieldValue.Duration)
+				dueOn = &end
+			}
+		}
+		if value == "" {
+			continue
+		}
+
+		i, ok := index[value]
+		if !ok {
+			i = len(groups)
+			index[value] = i
+			groups = append(groups, ProjectGroup{Value: value, DueOn: dueOn})
+		}
+
+		// Merged pull requests are closed as far as planning is concerned
+		state := strings.ToLower(item.Content.State)
+		if state == "merged" {
+			state = "closed"
+		}
+		issue := Issue{
+			Title:     item.Content.Title,
+			Number:    item.Content.Number,
+			State:     state,
+			Labels:    item.Content.Labels.Nodes,
+			HTMLURL:   item.Content.URL,
+			Body:      item.Content.Body,
+			CreatedAt: item.Content.CreatedAt,
+			ClosedAt:  item.Content.ClosedAt,
+		}
+		if len(item.Content.Assignees.Nodes) > 0 {
+			issue.Assignee = &item.Content.Assignees.Nodes[0]
+		}
+		groups[i].Issues = append(groups[i].Issues, issue)
+	}
+
+	// Order iterations by start date
+	sort.SliceStable(groups, func(i, j int) bool {
+		si, iok := starts[groups[i].Value]
+		sj, jok := starts[groups[j].Value]
+		return iok && jok && si.Before(sj)
+	})
+	return groups
+}
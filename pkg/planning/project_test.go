@@ -0,0 +1,88 @@
+package planning
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupProjectItems(t *testing.T) {
+	var items []projectItem
+	err := json.Unmarshal([]byte(`[
+  {"fieldValueByName": {"title": "Iteration 2", "startDate": "2025-01-15", "duration": 14},
+   "content": {"title": "Fix crash", "number": 3, "state": "OPEN", "url": "https://github.com/owner/repo/issues/3",
+               "repository": {"nameWithOwner": "owner/repo"}, "labels": {"nodes": [{"name": "bug"}]},
+               "assignees": {"nodes": [{"login": "alice"}]}}},
+  {"fieldValueByName": {"title": "Iteration 1", "startDate": "2025-01-01", "duration": 14},
+   "content": {"title": "Add feature", "number": 1, "state": "CLOSED", "url": "https://github.com/owner/repo/issues/1",
+               "repository": {"nameWithOwner": "owner/repo"}}},
+  {"fieldValueByName": {"title": "Iteration 1", "startDate": "2025-01-01", "duration": 14},
+   "content": {"title": "Implement feature", "number": 2, "state": "MERGED", "url": "https://github.com/owner/repo/pull/2",
+               "repository": {"nameWithOwner": "owner/repo"}}},
+  {"fieldValueByName": {"title": "Iteration 1", "startDate": "2025-01-01", "duration": 14},
+   "content": {"title": "Other repository", "number": 4, "state": "OPEN", "url": "https://github.com/owner/other/issues/4",
+               "repository": {"nameWithOwner": "owner/other"}}},
+  {"fieldValueByName": null,
+   "content": {"title": "No iteration", "number": 5, "state": "OPEN", "url": "https://github.com/owner/repo/issues/5",
+               "repository": {"nameWithOwner": "owner/repo"}}},
+  {"fieldValueByName": {"title": "Iteration 1", "startDate": "2025-01-01", "duration": 14},
+   "content": {"title": "Draft issue"}}
+]`), &items)
+	require.NoError(t, err)
+
+	groups := groupProjectItems(items, "owner/repo", false)
+	require.Len(t, groups, 2)
+
+	// Iterations are ordered by start date, and end after their duration
+	assert.Equal(t, "Iteration 1", groups[0].Value)
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), *groups[0].DueOn)
+	require.Len(t, groups[0].Issues, 2)
+	assert.Equal(t, "closed", groups[0].Issues[0].State)
+	assert.Equal(t, "closed", groups[0].Issues[1].State)
+
+	assert.Equal(t, "Iteration 2", groups[1].Value)
+	require.Len(t, groups[1].Issues, 1)
+	assert.Equal(t, "open", groups[1].Issues[0].State)
+	assert.Equal(t, "alice", groups[1].Issues[0].Assignee.Login)
+	assert.Equal(t, []Label{{Name: "bug"}}, groups[1].Issues[0].Labels)
+
+	// Pull requests can be excluded
+	groups = groupProjectItems(items, "owner/repo", true)
+	assert.Len(t, groups[0].Issues, 1)
+
+	// Single-select options have no due date and keep their order
+	var options []projectItem
+	err = json.Unmarshal([]byte(`[
+  {"fieldValueByName": {"name": "v2.0"},
+   "content": {"number": 1, "state": "OPEN", "url": "https://github.com/owner/repo/issues/1", "repository": {"nameWithOwner": "owner/repo"}}},
+  {"fieldValueByName": {"name": "v1.0"},
+   "content": {"number": 2, "state": "OPEN", "url": "https://github.com/owner/repo/issues/2", "repository": {"nameWithOwner": "owner/repo"}}}
+]`), &options)
+	require.NoError(t, err)
+	groups = groupProjectItems(options, "owner/repo", false)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "v2.0", groups[0].Value)
+	assert.Nil(t, groups[0].DueOn)
+	assert.Equal(t, "v1.0", groups[1].Value)
+}
+
+func TestGeneratePlanningContentForProject(t *testing.T) {
+	data := TemplateData{
+		Milestone: Milestone{Title: "Iteration 1", Number: 3, HTMLURL: "https://github.com/orgs/owner/projects/3"},
+		Stats:     MilestoneStats{TotalIssues: 2, CompletedIssues: 1},
+		RepoOwner: "owner",
+		RepoName:  "repo",
+		Project:   &Project{Title: "Roadmap", Number: 3, URL: "https://github.com/orgs/owner/projects/3", Field: "Iteration"},
+	}
+	m := &Manager{}
+
+	content, err := m.generatePlanningContentWithTime(data, "", time.Time{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "- [Total Issues: 2](https://github.com/orgs/owner/projects/3)\n  - ✅ Completed: 1\n  - 🚧 In Progress: 1\n")
+	assert.Contains(t, content, "- Data comes from [Project #3: Roadmap](https://github.com/orgs/owner/projects/3), Iteration \"Iteration 1\"\n")
+	assert.Contains(t, content, "## Links\n- 📊 [All project items](https://github.com/orgs/owner/projects/3)\n---\n")
+	assert.NotContains(t, content, "milestone%3A")
+}
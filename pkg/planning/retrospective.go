@@ -0,0 +1,214 @@
+package planning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/util/prompt"
+)
+
+// Retrospective represents the final report of a closed milestone
+type Retrospective struct {
+	ClosedAt      *time.Time
+	Slip          string     // Closing date versus due date, e.g. "3 days late", empty without due date
+	Shipped       []Issue    // Issues closed in the milestone
+	CarriedOver   []Issue    // Issues left open in the milestone
+	NextMilestone *Milestone // Milestone the issues left open are moved to, if any
+}
+
+// Close writes the final retrospective of a closed milestone to its planning
+// issue and closes the issue. With opts.CarryOver, the issues left open are
+// moved to the next open milestone.
+func (m *Manager) Close(ctx context.Context, owner, repo string, milestoneNumber int, opts Options) error {
+	log.Debug("Closing planning issue for milestone #%d in %s/%s", milestoneNumber, owner, repo)
+
+	// Get milestone
+	var milestone Milestone
+	path := fmt.Sprintf("repos/%s/%s/milestones/%d", owner, repo, milestoneNumber)
+	if err := m.client.Get(path, &milestone); err != nil {
+		return fmt.Errorf("failed to get milestone: %w", err)
+	}
+	if milestone.State != "closed" {
+		return fmt.Errorf("milestone #%d (%s) is not closed yet", milestone.Number, milestone.Title)
+	}
+
+	// Skip the milestones whose retrospective is done already
+	planningIssue, _, err := m.findPlanningIssue(owner, repo, milestone, opts)
+	if err != nil {
+		return err
+	}
+	if planningIssue != nil && planningIssue.State == "closed" {
+		log.Success("Planning issue #%d for milestone '%s' is already closed, skipping retrospective", planningIssue.Number, milestone.Title)
+		return nil
+	}
+
+	// Get all issues in the milestone
+	issues, err := m.ListMilestoneIssues(ctx, owner, repo, milestoneNumber, opts.ExcludePR)
+	if err != nil {
+		return err
+	}
+	m.resolveLinks(ctx, owner, repo, issues)
+
+	// Find the milestone the issues left open are carried over to
+	var next *Milestone
+	if opts.CarryOver {
+		milestones, err := m.ListOpenMilestones(ctx, owner, repo)
+		if err != nil {
+			return err
+		}
+		next = nextMilestone(milestones)
+		if next == nil {
+			log.Warn("No open milestone found to carry over the issues left open")
+		}
+	}
+
+	// Prepare data for template, without the burndown and forecast which are
+	// only relevant while the milestone is open
+	data := m.prepareTemplateData(milestone, issues, opts)
+	data.Stats.Burndown = nil
+	data.Retrospective = newRetrospective(milestone, issues, next)
+
+	if err := m.updatePlanning(owner, repo, data, opts); err != nil {
+		return err
+	}
+
+	if next != nil && len(data.Retrospective.CarriedOver) > 0 {
+		return m.carryOver(owner, repo, data.Retrospective.CarriedOver, *next, opts)
+	}
+	return nil
+}
+
+// ListPendingRetrospectives returns the closed milestones whose planning issue
+// is still open
+func (m *Manager) ListPendingRetrospectives(ctx context.Context, owner, repo string, opts Options) ([]Milestone, error) {
+	milestones, err := m.listMilestones(owner, repo, "closed")
+	if err != nil {
+		return nil, err
+	}
+
+	planningIssues, err := m.listPlanningIssues(owner, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Milestone
+	for _, milestone := range milestones {
+		planningTitle, err := renderPlanningTitle(milestone, opts)
+		if err != nil {
+			return nil, err
+		}
+		issue := selectPlanningIssue(planningIssues, planningTitle)
+		if issue != nil && issue.State == "open" {
+			pending = append(pending, milestone)
+		}
+	}
+	return pending, nil
+}
+
+// carryOver moves the issues to the next milestone
+func (m *Manager) carryOver(owner, repo string, issues []Issue, next Milestone, opts Options) error {
+	log.Info("Carrying over %d issues left open to milestone '%s'", len(issues), next.Title)
+	for _, issue := range issues {
+		log.L(1).P("→").Log("#%d %s", issue.Number, issue.Title)
+	}
+
+	if opts.Diff || opts.DryRun {
+		log.Warn("Dry-run mode, skipping carry-over")
+		return nil
+	}
+	if !opts.AutoConfirm {
+		confirmed, err := prompt.AskForConfirmation("Do you want to move these issues?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info("Carry-over cancelled")
+			return nil
+		}
+	}
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{"milestone": next.Number})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	for _, issue := range issues {
+		path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, issue.Number)
+		if err := m.client.Patch(path, bytes.NewReader(bodyBytes), nil); err != nil {
+			return fmt.Errorf("failed to move issue #%d: %w", issue.Number, err)
+		}
+	}
+	log.Success("Successfully moved %d issues to milestone '%s'", len(issues), next.Title)
+	return nil
+}
+
+// newRetrospective splits the issues of the closed milestone into shipped and
+// carried over issues, and computes the slip of the milestone
+func newRetrospective(milestone Milestone, issues []Issue, next *Milestone) *Retrospective {
+	retrospective := &Retrospective{
+		ClosedAt:      milestone.ClosedAt,
+		Slip:          formatSlip(milestone.DueOn, milestone.ClosedAt),
+		NextMilestone: next,
+	}
+	for _, issue := range issues {
+		if issue.State == "closed" {
+			retrospective.Shipped = append(retrospective.Shipped, issue)
+		} else {
+			retrospective.CarriedOver = append(retrospective.CarriedOver, issue)
+		}
+	}
+	sort.Slice(retrospective.Shipped, func(i, j int) bool {
+		return retrospective.Shipped[i].Number < retrospective.Shipped[j].Number
+	})
+	sort.Slice(retrospective.CarriedOver, func(i, j int) bool {
+		return retrospective.CarriedOver[i].Number < retrospective.CarriedOver[j].Number
+	})
+	return retrospective
+}
+
+// formatSlip describes when a milestone was closed compared to its due date
+func formatSlip(dueOn, closedAt *time.Time) string {
+	if dueOn == nil || closedAt == nil {
+		return ""
+	}
+	days := int(math.Round(closedAt.Sub(*dueOn).Hours() / 24))
+	switch {
+	case days == 0:
+		return "on time"
+	case days == 1:
+		return "1 day late"
+	case days > 1:
+		return fmt.Sprintf("%d days late", days)
+	case days == -1:
+		return "1 day early"
+	default:
+		return fmt.Sprintf("%d days early", -days)
+	}
+}
+
+// nextMilestone returns the open milestone due first, milestones without due
+// date coming last
+func nextMilestone(milestones []Milestone) *Milestone {
+	var next *Milestone
+	for i := range milestones {
+		milestone := &milestones[i]
+		if next == nil {
+			next = milestone
+			continue
+		}
+		switch {
+		case milestone.DueOn != nil && next.DueOn == nil:
+			next = milestone
+		case milestone.DueOn != nil && next.DueOn != nil && milestone.DueOn.Before(*next.DueOn):
+			next = milestone
+		case milestone.DueOn == nil && next.DueOn == nil && milestone.Number < next.Number:
+			next = milestone
+		}
+	}
+	return next
+}
@@ -0,0 +1,185 @@
+package planning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTransport sends the requests to the GitHub API to a test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestManager returns a manager whose GitHub API requests are handled by the handler
+func newTestManager(t *testing.T, handler http.HandlerFunc) *Manager {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: redirectTransport{target: target}})
+	require.NoError(t, err)
+	return NewManager(client, nil)
+}
+
+func TestFormatSlip(t *testing.T) {
+	due := time.Date(2025, 3, 15, 7, 0, 0, 0, time.UTC)
+	closedAt := func(days int) *time.Time {
+		date := due.AddDate(0, 0, days).Add(2 * time.Hour)
+		return &date
+	}
+
+	assert.Equal(t, "on time", formatSlip(&due, closedAt(0)))
+	assert.Equal(t, "1 day late", formatSlip(&due, closedAt(1)))
+	assert.Equal(t, "5 days late", formatSlip(&due, closedAt(5)))
+	assert.Equal(t, "1 day early", formatSlip(&due, closedAt(-1)))
+	assert.Equal(t, "3 days early", formatSlip(&due, closedAt(-3)))
+	assert.Empty(t, formatSlip(nil, closedAt(0)))
+}
+
+func TestNextMilestone(t *testing.T) {
+	early := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	next := nextMilestone([]Milestone{
+		{Number: 4},
+		{Number: 3, DueOn: &late},
+		{Number: 5, DueOn: &early},
+	})
+	require.NotNil(t, next)
+	assert.Equal(t, 5, next.Number)
+
+	next = nextMilestone([]Milestone{{Number: 7}, {Number: 6}})
+	require.NotNil(t, next)
+	assert.Equal(t, 6, next.Number)
+
+	assert.Nil(t, nextMilestone(nil))
+}
+
+func TestNewRetrospective(t *testing.T) {
+	due := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	closedAt := time.Date(2025, 3, 18, 0, 0, 0, 0, time.UTC)
+	milestone := Milestone{Title: "v1.0.0", Number: 1, State: "closed", DueOn: &due, ClosedAt: &closedAt}
+	next := &Milestone{Title: "v1.1.0", Number: 2}
+	issues := []Issue{
+		{Number: 3, State: "closed"},
+		{Number: 2, State: "open"},
+		{Number: 1, State: "closed"},
+	}
+
+	retrospective := newRetrospective(milestone, issues, next)
+	assert.Equal(t, "3 days late", retrospective.Slip)
+	assert.Equal(t, &closedAt, retrospective.ClosedAt)
+	assert.Equal(t, []Issue{{Number: 1, State: "closed"}, {Number: 3, State: "closed"}}, retrospective.Shipped)
+	assert.Equal(t, []Issue{{Number: 2, State: "open"}}, retrospective.CarriedOver)
+	assert.Equal(t, next, retrospective.NextMilestone)
+}
+
+func TestGeneratePlanningContentWithRetrospective(t *testing.T) {
+	closedAt := time.Date(2025, 3, 18, 0, 0, 0, 0, time.UTC)
+	data := TemplateData{
+		Milestone: Milestone{Title: "v1.0.0", Number: 1, HTMLURL: "https://github.com/owner/repo/milestone/1", ClosedAt: &closedAt},
+		Stats:     MilestoneStats{TotalIssues: 2, CompletedIssues: 1},
+		Retrospective: &Retrospective{
+			ClosedAt:      &closedAt,
+			Slip:          "3 days late",
+			Shipped:       []Issue{{Number: 1, Assignee: &User{Login: "alice"}}},
+			CarriedOver:   []Issue{{Number: 2}},
+			NextMilestone: &Milestone{Title: "v1.1.0", HTMLURL: "https://github.com/owner/repo/milestone/2"},
+		},
+	}
+	m := &Manager{}
+
+	content, err := m.generatePlanningContentWithTime(data, "", time.Time{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "(https://github.com/owner/repo/milestone/1)\n\n## Retrospective\n"+
+		"> This milestone was closed on March 18, 2025 (3 days late), this planning is final.\n\n"+
+		"### 🚀 Shipped (1)\n- #1 (@alice)\n\n"+
+		"### ⏭️ Carried Over (1)\n> Moved to milestone [v1.1.0](https://github.com/owner/repo/milestone/2).\n\n- #2\n\n"+
+		"## Description\n")
+
+	data.Retrospective = &Retrospective{ClosedAt: &closedAt}
+	content, err = m.generatePlanningContentWithTime(data, "", time.Time{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "closed on March 18, 2025, this planning is final.\n\n"+
+		"### 🚀 Shipped (0)\nNo issues were shipped.\n\n"+
+		"### ⏭️ Carried Over (0)\nNothing was carried over.\n\n## Description\n")
+}
+
+func TestListPendingRetrospectives(t *testing.T) {
+	// 101 planning issues take two pages, the planning issue of v1.0.0 is on the second one
+	var planningIssues []Issue
+	for number := 200; number > 99; number-- {
+		planningIssues = append(planningIssues, Issue{Number: number, Title: fmt.Sprintf("Other %d", number), State: "closed"})
+	}
+	planningIssues[100] = Issue{Number: 100, Title: "Planning: v1.0.0", State: "open"}
+	planningIssues[0] = Issue{Number: 200, Title: "Planning: v0.9.0", State: "closed"}
+
+	// 102 closed milestones take two pages as well, v1.0.0 is on the second one
+	var milestones []Milestone
+	for number := 1; number <= 100; number++ {
+		milestones = append(milestones, Milestone{Number: number, Title: fmt.Sprintf("v0.%d.0", number)})
+	}
+	milestones = append(milestones, Milestone{Number: 101, Title: "v1.0.0"}, Milestone{Number: 102, Title: "v1.1.0"})
+
+	requests := make(map[string]int)
+	m := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/repos/owner/repo/milestones":
+			assert.Equal(t, "closed", r.URL.Query().Get("state"))
+			page := milestones[:100]
+			if r.URL.Query().Get("page") == "2" {
+				page = milestones[100:]
+			}
+			_ = json.NewEncoder(w).Encode(page)
+		case "/repos/owner/repo/issues":
+			assert.Equal(t, "planning", r.URL.Query().Get("labels"))
+			page := planningIssues[:100]
+			if r.URL.Query().Get("page") == "2" {
+				page = planningIssues[100:]
+			}
+			_ = json.NewEncoder(w).Encode(page)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	pending, err := m.ListPendingRetrospectives(context.Background(), "owner", "repo", DefaultOptions())
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "v1.0.0", pending[0].Title)
+	assert.Equal(t, 2, requests["/repos/owner/repo/milestones"])
+	assert.Equal(t, 2, requests["/repos/owner/repo/issues"])
+}
+
+func TestCloseWithClosedPlanningIssue(t *testing.T) {
+	// The retrospective of a milestone whose planning issue is closed is done
+	// already, so the issues of the milestone are not even listed
+	m := newTestManager(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/owner/repo/milestones/1":
+			_, _ = w.Write([]byte(`{"number": 1, "title": "v0.9.0", "state": "closed"}`))
+		case r.URL.Path == "/repos/owner/repo/issues" && r.URL.Query().Get("labels") == "planning":
+			_, _ = w.Write([]byte(`[{"number": 200, "title": "Planning: v0.9.0", "state": "closed"}]`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	require.NoError(t, m.Close(context.Background(), "owner", "repo", 1, DefaultOptions()))
+}
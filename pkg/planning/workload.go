@@ -0,0 +1,74 @@
+package planning
+
+import "sort"
+
+// Workload represents the issues of a milestone held by an assignee
+type Workload struct {
+	Assignee         string
+	Open             int
+	Closed           int
+	OpenHighPriority int  // Open issues with one of the top two priority labels
+	Overloaded       bool // true if the assignee holds more open high-priority issues than allowed
+}
+
+// isHighPriority reports whether the priority level is one of the top two
+func isHighPriority(level int, priorities []string) bool {
+	return level < min(2, len(priorities))
+}
+
+// computeWorkloads returns the workload of each assignee of the issues, from the
+// assignee holding the most open high-priority issues
+func computeWorkloads(issues []Issue, priorities []string, maxHighPriority int) []Workload {
+	byAssignee := make(map[string]*Workload)
+	for _, issue := range issues {
+		if issue.Assignee == nil {
+			continue
+		}
+		workload, ok := byAssignee[issue.Assignee.Login]
+		if !ok {
+			workload = &Workload{Assignee: issue.Assignee.Login}
+			byAssignee[issue.Assignee.Login] = workload
+		}
+		if issue.State == "closed" {
+			workload.Closed++
+			continue
+		}
+		workload.Open++
+		if isHighPriority(GetPriorityLevel(issue.Labels, priorities), priorities) {
+			workload.OpenHighPriority++
+		}
+	}
+
+	workloads := make([]Workload, 0, len(byAssignee))
+	for _, workload := range byAssignee {
+		workload.Overloaded = workload.OpenHighPriority > maxHighPriority
+		workloads = append(workloads, *workload)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].OpenHighPriority != workloads[j].OpenHighPriority {
+			return workloads[i].OpenHighPriority > workloads[j].OpenHighPriority
+		}
+		if workloads[i].Open != workloads[j].Open {
+			return workloads[i].Open > workloads[j].Open
+		}
+		return workloads[i].Assignee < workloads[j].Assignee
+	})
+	return workloads
+}
+
+// findUnownedIssues returns the open issues without assignee, and the open
+// issues without priority label if priority labels are used
+func findUnownedIssues(issues []Issue, priorities []string) (unassigned, unprioritized []Issue) {
+	for _, issue := range issues {
+		if issue.State == "closed" {
+			continue
+		}
+		if issue.Assignee == nil {
+			unassigned = append(unassigned, issue)
+		}
+		if len(priorities) > 0 && GetPriorityLevel(issue.Labels, priorities) == len(priorities) {
+			unprioritized = append(unprioritized, issue)
+		}
+	}
+	return unassigned, unprioritized
+}
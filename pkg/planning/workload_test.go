@@ -0,0 +1,76 @@
+package planning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeWorkloads(t *testing.T) {
+	priorities := []string{"priority/high", "priority/medium", "priority/low"}
+	high := []Label{{Name: "priority/high"}}
+	medium := []Label{{Name: "priority/medium"}}
+	low := []Label{{Name: "priority/low"}}
+	issues := []Issue{
+		{Number: 1, State: "open", Labels: high, Assignee: &User{Login: "alice"}},
+		{Number: 2, State: "open", Labels: medium, Assignee: &User{Login: "alice"}},
+		{Number: 3, State: "closed", Labels: high, Assignee: &User{Login: "alice"}},
+		{Number: 4, State: "open", Labels: low, Assignee: &User{Login: "bob"}},
+		{Number: 5, State: "open", Labels: low, Assignee: &User{Login: "bob"}},
+		{Number: 6, State: "open", Labels: high, Assignee: &User{Login: "carol"}},
+		{Number: 7, State: "open", Labels: high},
+		{Number: 8, State: "open"},
+		{Number: 9, State: "closed"},
+	}
+
+	workloads := computeWorkloads(issues, priorities, 1)
+	assert.Equal(t, []Workload{
+		{Assignee: "alice", Open: 2, Closed: 1, OpenHighPriority: 2, Overloaded: true},
+		{Assignee: "carol", Open: 1, OpenHighPriority: 1},
+		{Assignee: "bob", Open: 2},
+	}, workloads)
+
+	unassigned, unprioritized := findUnownedIssues(issues, priorities)
+	require.Len(t, unassigned, 2)
+	assert.Equal(t, 7, unassigned[0].Number)
+	assert.Equal(t, 8, unassigned[1].Number)
+	require.Len(t, unprioritized, 1)
+	assert.Equal(t, 8, unprioritized[0].Number)
+
+	// Without priority labels nothing is high priority or unprioritized
+	workloads = computeWorkloads(issues, nil, 0)
+	assert.Zero(t, workloads[0].OpenHighPriority)
+	_, unprioritized = findUnownedIssues(issues, nil)
+	assert.Nil(t, unprioritized)
+}
+
+func TestGeneratePlanningContentWithTeamLoad(t *testing.T) {
+	data := TemplateData{
+		Milestone: Milestone{Title: "v1.0.0", Number: 1},
+		Stats: MilestoneStats{
+			TotalIssues: 4,
+			Workloads: []Workload{
+				{Assignee: "alice", Open: 4, Closed: 1, OpenHighPriority: 4, Overloaded: true},
+				{Assignee: "bob", Open: 1},
+			},
+		},
+		Priorities:          []string{"priority/high", "priority/medium"},
+		UnassignedIssues:    []Issue{{Number: 7}, {Number: 8}},
+		UnprioritizedIssues: []Issue{{Number: 8}},
+		MaxHighPriority:     3,
+	}
+	m := &Manager{}
+
+	content, err := m.generatePlanningContentWithTime(data, "", time.Time{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "\n## Team Load\n"+
+		"> ⚠️ marks assignees holding more than 3 open issues with `priority/high` and `priority/medium` priority labels.\n\n"+
+		"| Assignee | Open | Closed | Open High Priority |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| @alice ⚠️ | 4 | 1 | 4 |\n"+
+		"| @bob | 1 | 0 | 0 |\n\n"+
+		"- 👥 Unassigned (2): #7 #8\n"+
+		"- 🏷️ Without priority (1): #8\n")
+}
@@ -0,0 +1,595 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/util/diff"
+	"github.com/elliotxx/osp/pkg/util/gitutil"
+	"github.com/elliotxx/osp/pkg/util/issueutil"
+	"github.com/elliotxx/osp/pkg/util/tmplutil"
+)
+
+//go:embed templates/release-notes.gotmpl
+var templates embed.FS
+
+const (
+	// perPage is the page size used when listing from the GitHub API
+	perPage = 100
+
+	// maxSearchPages is the number of pages the GitHub search API returns at most
+	maxSearchPages = 10
+)
+
+// Manager handles release notes
+type Manager struct {
+	client *api.RESTClient
+}
+
+// NewManager creates a new release notes manager
+func NewManager(client *api.RESTClient) *Manager {
+	return &Manager{
+		client: client,
+	}
+}
+
+// User represents a GitHub user
+type User struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// IsBot reports whether the user is a bot account
+func (u User) IsBot() bool {
+	return u.Type == "Bot" || strings.HasSuffix(u.Login, "[bot]")
+}
+
+// Label represents a GitHub label
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Issue represents a GitHub issue or pull request
+type Issue struct {
+	Title       string     `json:"title"`
+	Number      int        `json:"number"`
+	User        User       `json:"user"`
+	Labels      []Label    `json:"labels"`
+	HTMLURL     string     `json:"html_url"`
+	StateReason string     `json:"state_reason"`
+	ClosedAt    *time.Time `json:"closed_at"`
+	PullRequest *struct {
+		MergedAt *time.Time `json:"merged_at"`
+	} `json:"pull_request"`
+}
+
+// IsPR reports whether the issue is a pull request
+func (i Issue) IsPR() bool {
+	return i.PullRequest != nil
+}
+
+// Milestone represents a GitHub milestone
+type Milestone struct {
+	Title   string `json:"title"`
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Contributor represents the author of merged pull requests in a release
+type Contributor struct {
+	Login            string
+	PullRequests     int
+	FirstPullRequest int  // Number of the earliest merged pull request in the release
+	FirstTime        bool // true if the user had no merged pull request before the release
+}
+
+// Metrics represents the participation in a release
+type Metrics struct {
+	MergedPRs       int
+	ClosedIssues    int
+	Contributors    int
+	NewContributors int
+	Participants    int // Distinct authors of the merged pull requests and closed issues
+}
+
+// Options represents release notes options
+type Options struct {
+	From        string   // Tag the release starts after, defaults to the tag preceding To in the local clone
+	To          string   // Tag of the release, defaults to the latest changes of the default branch
+	Milestone   int      // If set, collect the milestone instead of a tag range
+	Categories  []string // Labels used to classify issues and pull requests by type
+	Template    string   // Path of a custom release notes template, on local disk or inside the repository
+	DryRun      bool     // If true, only show preview without making changes
+	AutoConfirm bool     // If true, skip confirmation prompt
+}
+
+// DefaultOptions returns default release notes options. The categories are
+// the same as the ones used by planning.
+func DefaultOptions() Options {
+	return Options{
+		Categories:  []string{"bug", "enhancement", "documentation"},
+		DryRun:      false,
+		AutoConfirm: false,
+	}
+}
+
+// TemplateData represents the data passed to the template
+type TemplateData struct {
+	Title                     string // Tag or milestone title of the release
+	From                      time.Time
+	To                        time.Time
+	CompareURL                string // Empty for milestones
+	Milestone                 *Milestone
+	Categories                []string
+	PullRequests              map[string][]Issue
+	UncategorizedPullRequests []Issue
+	Issues                    map[string][]Issue
+	UncategorizedIssues       []Issue
+	Contributors              []Contributor
+	NewContributors           []Contributor
+	Metrics                   Metrics
+	RepoOwner                 string
+	RepoName                  string
+}
+
+// Collect collects the merged pull requests and closed issues of the release
+func (m *Manager) Collect(ctx context.Context, owner, repo string, opts Options) (TemplateData, error) {
+	data := TemplateData{Categories: opts.Categories, RepoOwner: owner, RepoName: repo}
+
+	var items []Issue
+	var err error
+	if opts.Milestone > 0 {
+		var milestone Milestone
+		path := fmt.Sprintf("repos/%s/%s/milestones/%d", owner, repo, opts.Milestone)
+		if err := m.client.Get(path, &milestone); err != nil {
+			return data, fmt.Errorf("failed to get milestone: %w", err)
+		}
+		log.Debug("Found milestone: %s (#%d)", milestone.Title, milestone.Number)
+		data.Milestone = &milestone
+		data.Title = milestone.Title
+
+		items, err = m.listMilestoneItems(owner, repo, opts.Milestone)
+		if err != nil {
+			return data, err
+		}
+		data.From, data.To = timeRange(items)
+	} else {
+		from, to := opts.From, opts.To
+		if from == "" {
+			if to == "" {
+				from, err = gitutil.GetLatestTag()
+			} else {
+				from, err = gitutil.GetPreviousTag(to)
+			}
+			if err != nil {
+				return data, fmt.Errorf("failed to find the previous tag in the local clone, specify it explicitly: %w", err)
+			}
+			log.Debug("Using tag %s as the start of the release", from)
+		}
+
+		data.From, err = m.getCommitTime(owner, repo, from)
+		if err != nil {
+			return data, err
+		}
+		data.To = time.Now().UTC()
+		data.Title = "Unreleased"
+		head := "HEAD"
+		if to != "" {
+			data.To, err = m.getCommitTime(owner, repo, to)
+			if err != nil {
+				return data, err
+			}
+			data.Title = to
+			head = to
+		}
+		data.CompareURL = fmt.Sprintf("https://github.com/%s/%s/compare/%s...%s", owner, repo, from, head)
+
+		items, err = m.searchRange(owner, repo, data.From, data.To)
+		if err != nil {
+			return data, err
+		}
+	}
+
+	// Split pull requests and issues
+	var prs, issues []Issue
+	for _, item := range items {
+		if item.IsPR() {
+			prs = append(prs, item)
+		} else {
+			issues = append(issues, item)
+		}
+	}
+	log.Debug("Found %d merged pull requests and %d closed issues", len(prs), len(issues))
+
+	// Check which authors have never had a pull request merged before
+	firstTime := make(map[string]bool)
+	for _, pr := range prs {
+		login := pr.User.Login
+		if pr.User.IsBot() {
+			continue
+		}
+		if _, ok := firstTime[login]; ok {
+			continue
+		}
+		before, err := m.hasMergedPRsBefore(owner, repo, login, data.From)
+		if err != nil {
+			return data, err
+		}
+		firstTime[login] = !before
+	}
+
+	prepareTemplateData(&data, prs, issues, firstTime)
+	return data, nil
+}
+
+// Generate collects the release and renders its notes with the built-in
+// template, or the custom one of the options
+func (m *Manager) Generate(ctx context.Context, owner, repo string, opts Options) (TemplateData, string, error) {
+	data, err := m.Collect(ctx, owner, repo, opts)
+	if err != nil {
+		return data, "", err
+	}
+
+	// Load custom template if specified
+	var tmplText string
+	if opts.Template != "" {
+		tmplText, err = tmplutil.Load(m.client, fmt.Sprintf("%s/%s", owner, repo), opts.Template)
+		if err != nil {
+			return data, "", fmt.Errorf("failed to load release notes template: %w", err)
+		}
+	}
+
+	content, err := generateContent(data, tmplText)
+	if err != nil {
+		return data, "", fmt.Errorf("failed to generate release notes: %w", err)
+	}
+	log.Debug("Generated release notes with %d bytes", len(content))
+	return data, content, nil
+}
+
+// SaveDraft creates a draft release for the tag with the release notes, or
+// updates the existing draft. Published releases are never modified.
+func (m *Manager) SaveDraft(owner, repo, tag, content string, opts Options) error {
+	existing, err := m.findRelease(owner, repo, tag)
+	if err != nil {
+		return err
+	}
+	if existing != nil && !existing.Draft {
+		return fmt.Errorf("release %s is already published, refusing to modify it", tag)
+	}
+
+	// Show preview
+	if existing == nil {
+		log.Info("Creating new draft release for tag %s", tag)
+
+		// Preview the content
+		log.C(log.ColorBlue).P("↓").Log("Preview of the release notes:")
+		log.C(log.ColorCyan).Log("%s", content)
+	} else {
+		log.Info("Updating existing draft release for tag %s", tag)
+
+		// Skip the update if nothing changed
+		if diff.Equal(existing.Body, content) {
+			log.Success("Draft release %s is up to date, skipping update", tag)
+			return nil
+		}
+
+		// Preview the changes
+		log.C(log.ColorBlue).P("↓").Log("Changes to the release notes:")
+		diff.Print(existing.Body, content)
+	}
+
+	existingURL := ""
+	if existing != nil {
+		existingURL = existing.HTMLURL
+	}
+	confirmed, err := issueutil.Confirm("draft release", existingURL, issueutil.Options{DryRun: opts.DryRun, AutoConfirm: opts.AutoConfirm})
+	if err != nil || !confirmed {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"body": content,
+	}
+	if existing == nil {
+		body["tag_name"] = tag
+		body["name"] = tag
+		body["draft"] = true
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	var response release
+	if existing == nil {
+		path := fmt.Sprintf("repos/%s/%s/releases", owner, repo)
+		if err := m.client.Post(path, bytes.NewReader(bodyBytes), &response); err != nil {
+			return fmt.Errorf("failed to create draft release: %w", err)
+		}
+		log.Success("Successfully created draft release %s", tag).
+			L(1).P("→").Log("Release URL: %s", response.HTMLURL)
+	} else {
+		path := fmt.Sprintf("repos/%s/%s/releases/%d", owner, repo, existing.ID)
+		if err := m.client.Patch(path, bytes.NewReader(bodyBytes), &response); err != nil {
+			return fmt.Errorf("failed to update draft release: %w", err)
+		}
+		log.Success("Successfully updated draft release %s", tag).
+			L(1).P("→").Log("Release URL: %s", response.HTMLURL)
+	}
+
+	return nil
+}
+
+// release represents a GitHub release
+type release struct {
+	ID      int64  `json:"id"`
+	TagName string `json:"tag_name"`
+	Body    string `json:"body"`
+	Draft   bool   `json:"draft"`
+	HTMLURL string `json:"html_url"`
+}
+
+// findRelease returns the release of the tag, including drafts, or nil if there is none
+func (m *Manager) findRelease(owner, repo, tag string) (*release, error) {
+	var releases []release
+	path := fmt.Sprintf("repos/%s/%s/releases?per_page=%d", owner, repo, perPage)
+	if err := m.client.Get(path, &releases); err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	for i := range releases {
+		if releases[i].TagName == tag {
+			return &releases[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// getCommitTime returns the committer time of the commit a ref points to
+func (m *Manager) getCommitTime(owner, repo, ref string) (time.Time, error) {
+	var commit struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	path := fmt.Sprintf("repos/%s/%s/commits/%s", owner, repo, url.PathEscape(ref))
+	if err := m.client.Get(path, &commit); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get commit of %s: %w", ref, err)
+	}
+	return commit.Commit.Committer.Date, nil
+}
+
+// listMilestoneItems returns the merged pull requests and completed issues of the milestone
+func (m *Manager) listMilestoneItems(owner, repo string, milestone int) ([]Issue, error) {
+	var items []Issue
+	for page := 1; ; page++ {
+		var issues []Issue
+		path := fmt.Sprintf("repos/%s/%s/issues?milestone=%d&state=closed&page=%d&per_page=%d", owner, repo, milestone, page, perPage)
+		if err := m.client.Get(path, &issues); err != nil {
+			return nil, fmt.Errorf("failed to get issues: %w", err)
+		}
+		for _, issue := range issues {
+			if issue.IsPR() && issue.PullRequest.MergedAt == nil {
+				continue
+			}
+			if !issue.IsPR() && issue.StateReason == "not_planned" {
+				continue
+			}
+			items = append(items, issue)
+		}
+		log.Debug("Got %d issues from page %d", len(issues), page)
+		if len(issues) < perPage {
+			return items, nil
+		}
+	}
+}
+
+// searchRange returns the pull requests merged and the issues completed between from and to
+func (m *Manager) searchRange(owner, repo string, from, to time.Time) ([]Issue, error) {
+	prQuery, issueQuery := rangeQueries(owner, repo, from, to)
+	prs, err := m.search(prQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search merged pull requests: %w", err)
+	}
+	issues, err := m.search(issueQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search closed issues: %w", err)
+	}
+	return append(prs, issues...), nil
+}
+
+// rangeQueries returns the search queries of the pull requests merged and the
+// issues completed after from, up to and including to. The lower bound is
+// exclusive, so that an item merged at the time of the previous release is not
+// listed in both releases.
+func rangeQueries(owner, repo string, from, to time.Time) (string, string) {
+	after, until := from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)
+	prQuery := fmt.Sprintf("repo:%s/%s is:pr is:merged merged:>%s merged:<=%s", owner, repo, after, until)
+	issueQuery := fmt.Sprintf("repo:%s/%s is:issue is:closed reason:completed closed:>%s closed:<=%s", owner, repo, after, until)
+	return prQuery, issueQuery
+}
+
+// search returns the issues and pull requests matching the search query
+func (m *Manager) search(query string) ([]Issue, error) {
+	var all []Issue
+	for page := 1; page <= maxSearchPages; page++ {
+		var result struct {
+			Items []Issue `json:"items"`
+		}
+		path := fmt.Sprintf("search/issues?q=%s&sort=created&order=asc&per_page=%d&page=%d", url.QueryEscape(query), perPage, page)
+		if err := m.client.Get(path, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Items...)
+		if len(result.Items) < perPage {
+			return all, nil
+		}
+	}
+	log.Warn("Only the first %d search results are included", maxSearchPages*perPage)
+	return all, nil
+}
+
+// hasMergedPRsBefore reports whether the user had any pull request merged before the time
+func (m *Manager) hasMergedPRsBefore(owner, repo, login string, before time.Time) (bool, error) {
+	var result struct {
+		TotalCount int `json:"total_count"`
+	}
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged author:%s merged:<%s", owner, repo, login, before.UTC().Format(time.RFC3339))
+	path := fmt.Sprintf("search/issues?q=%s&per_page=1", url.QueryEscape(query))
+	if err := m.client.Get(path, &result); err != nil {
+		return false, fmt.Errorf("failed to search pull requests of %s: %w", login, err)
+	}
+	return result.TotalCount > 0, nil
+}
+
+// timeRange returns the earliest and latest close times of the items
+func timeRange(items []Issue) (from, to time.Time) {
+	for _, item := range items {
+		if item.ClosedAt == nil {
+			continue
+		}
+		if from.IsZero() || item.ClosedAt.Before(from) {
+			from = *item.ClosedAt
+		}
+		if item.ClosedAt.After(to) {
+			to = *item.ClosedAt
+		}
+	}
+	return from, to
+}
+
+// prepareTemplateData groups the pull requests and issues by category and
+// computes the contributors and participation metrics. firstTime tells
+// whether each pull request author is contributing for the first time.
+func prepareTemplateData(data *TemplateData, prs, issues []Issue, firstTime map[string]bool) {
+	sortByNumber := func(items []Issue) {
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].Number < items[j].Number
+		})
+	}
+	sortByNumber(prs)
+	sortByNumber(issues)
+
+	data.PullRequests, data.UncategorizedPullRequests = groupByCategory(prs, data.Categories)
+	data.Issues, data.UncategorizedIssues = groupByCategory(issues, data.Categories)
+
+	// Collect the authors of merged pull requests
+	contributors := make(map[string]*Contributor)
+	for _, pr := range prs {
+		if pr.User.IsBot() {
+			continue
+		}
+		c, ok := contributors[pr.User.Login]
+		if !ok {
+			c = &Contributor{Login: pr.User.Login, FirstPullRequest: pr.Number, FirstTime: firstTime[pr.User.Login]}
+			contributors[pr.User.Login] = c
+		}
+		c.PullRequests++
+	}
+	data.Contributors = nil
+	data.NewContributors = nil
+	for _, c := range contributors {
+		data.Contributors = append(data.Contributors, *c)
+	}
+	sort.Slice(data.Contributors, func(i, j int) bool {
+		if data.Contributors[i].PullRequests != data.Contributors[j].PullRequests {
+			return data.Contributors[i].PullRequests > data.Contributors[j].PullRequests
+		}
+		return data.Contributors[i].Login < data.Contributors[j].Login
+	})
+	for _, c := range data.Contributors {
+		if c.FirstTime {
+			data.NewContributors = append(data.NewContributors, c)
+		}
+	}
+	sort.Slice(data.NewContributors, func(i, j int) bool {
+		return data.NewContributors[i].FirstPullRequest < data.NewContributors[j].FirstPullRequest
+	})
+
+	// Count everyone who authored a pull request or an issue
+	participants := make(map[string]bool)
+	for _, item := range append(append([]Issue(nil), prs...), issues...) {
+		if !item.User.IsBot() {
+			participants[item.User.Login] = true
+		}
+	}
+
+	data.Metrics = Metrics{
+		MergedPRs:       len(prs),
+		ClosedIssues:    len(issues),
+		Contributors:    len(data.Contributors),
+		NewContributors: len(data.NewContributors),
+		Participants:    len(participants),
+	}
+}
+
+// groupByCategory groups the items by the category labels. An item with
+// several category labels is listed under each of them.
+func groupByCategory(items []Issue, categories []string) (map[string][]Issue, []Issue) {
+	grouped := make(map[string][]Issue)
+	var uncategorized []Issue
+	for _, item := range items {
+		categorized := false
+		for _, category := range categories {
+			for _, label := range item.Labels {
+				if strings.EqualFold(label.Name, category) {
+					grouped[category] = append(grouped[category], item)
+					categorized = true
+					break
+				}
+			}
+		}
+		if !categorized {
+			uncategorized = append(uncategorized, item)
+		}
+	}
+	return grouped, uncategorized
+}
+
+// generateContent generates the release notes using the template. If tmplText
+// is empty, the built-in template is used.
+func generateContent(data TemplateData, tmplText string) (string, error) {
+	funcMap := template.FuncMap{
+		"formatDate": func(date time.Time) string {
+			return date.Format("January 2, 2006")
+		},
+		"urlEncode": url.QueryEscape,
+	}
+
+	// Load template with functions
+	tmpl := template.New("release-notes.gotmpl").Funcs(funcMap)
+	var err error
+	if tmplText == "" {
+		tmpl, err = tmpl.ParseFS(templates, "templates/release-notes.gotmpl")
+	} else {
+		tmpl, err = tmpl.Parse(tmplText)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// Check the template against the template data before rendering
+	if err := tmplutil.Validate(tmpl, data); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	// Execute template
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
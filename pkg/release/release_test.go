@@ -0,0 +1,109 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPR(number int, login string, labels ...string) Issue {
+	issue := newIssue(number, login, labels...)
+	mergedAt := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	issue.PullRequest = &struct {
+		MergedAt *time.Time `json:"merged_at"`
+	}{MergedAt: &mergedAt}
+	return issue
+}
+
+func newIssue(number int, login string, labels ...string) Issue {
+	issue := Issue{Number: number, Title: "Item", User: User{Login: login}}
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, Label{Name: label})
+	}
+	return issue
+}
+
+func TestPrepareTemplateData(t *testing.T) {
+	prs := []Issue{
+		newPR(12, "bob", "enhancement"),
+		newPR(10, "alice", "Bug"),
+		newPR(11, "alice"),
+		newPR(13, "dependabot[bot]"),
+	}
+	issues := []Issue{
+		newIssue(3, "carol", "bug"),
+		newIssue(2, "alice", "question"),
+	}
+	data := TemplateData{Categories: DefaultOptions().Categories}
+
+	prepareTemplateData(&data, prs, issues, map[string]bool{"bob": true})
+
+	assert.Equal(t, []int{10}, numbers(data.PullRequests["bug"]))
+	assert.Equal(t, []int{12}, numbers(data.PullRequests["enhancement"]))
+	assert.Equal(t, []int{11, 13}, numbers(data.UncategorizedPullRequests))
+	assert.Equal(t, []int{3}, numbers(data.Issues["bug"]))
+	assert.Equal(t, []int{2}, numbers(data.UncategorizedIssues))
+
+	assert.Equal(t, []Contributor{
+		{Login: "alice", PullRequests: 2, FirstPullRequest: 10},
+		{Login: "bob", PullRequests: 1, FirstPullRequest: 12, FirstTime: true},
+	}, data.Contributors)
+	assert.Equal(t, []Contributor{
+		{Login: "bob", PullRequests: 1, FirstPullRequest: 12, FirstTime: true},
+	}, data.NewContributors)
+	assert.Equal(t, Metrics{MergedPRs: 4, ClosedIssues: 2, Contributors: 2, NewContributors: 1, Participants: 3}, data.Metrics)
+}
+
+func TestGenerateContent(t *testing.T) {
+	data := TemplateData{
+		Title:      "v0.4.0",
+		CompareURL: "https://github.com/owner/repo/compare/v0.3.0...v0.4.0",
+		Categories: DefaultOptions().Categories,
+		RepoOwner:  "owner",
+		RepoName:   "repo",
+	}
+	prepareTemplateData(&data, []Issue{newPR(10, "alice", "bug"), newPR(11, "bob")}, []Issue{newIssue(3, "carol", "bug")}, map[string]bool{"bob": true})
+
+	content, err := generateContent(data, "")
+	assert.NoError(t, err)
+	assert.Contains(t, content, "### bug\n- Item by @alice in #10\n")
+	assert.Contains(t, content, "### Other Changes\n- Item by @bob in #11\n")
+	assert.Contains(t, content, "## Closed Issues\n\n### bug\n- #3 Item\n")
+	assert.Contains(t, content, "- @bob made their first contribution in #11")
+	assert.Contains(t, content, "- @alice (1 pull request)")
+	assert.Contains(t, content, "- 👥 Contributors: 2 (1 new)")
+	assert.Contains(t, content, "**Full Changelog**: https://github.com/owner/repo/compare/v0.3.0...v0.4.0")
+	assert.NotContains(t, content, "### enhancement")
+
+	content, err = generateContent(data, "{{ .Title }}: {{ .Metrics.MergedPRs }}")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.4.0: 2", content)
+
+	_, err = generateContent(data, "{{ .Missing }}")
+	assert.Error(t, err)
+}
+
+func TestTimeRange(t *testing.T) {
+	early := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	from, to := timeRange([]Issue{{ClosedAt: &late}, {}, {ClosedAt: &early}})
+	assert.Equal(t, early, from)
+	assert.Equal(t, late, to)
+}
+
+func TestRangeQueries(t *testing.T) {
+	from := time.Date(2025, 1, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	prQuery, issueQuery := rangeQueries("owner", "repo", from, to)
+	assert.Equal(t, "repo:owner/repo is:pr is:merged merged:>2025-01-01T00:00:00Z merged:<=2025-02-01T00:00:00Z", prQuery)
+	assert.Equal(t, "repo:owner/repo is:issue is:closed reason:completed closed:>2025-01-01T00:00:00Z closed:<=2025-02-01T00:00:00Z", issueQuery)
+}
+
+func numbers(issues []Issue) []int {
+	result := make([]int, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, issue.Number)
+	}
+	return result
+}
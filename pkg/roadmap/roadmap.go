@@ -0,0 +1,400 @@
+package roadmap
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/planning"
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/elliotxx/osp/pkg/util/issueutil"
+	"github.com/elliotxx/osp/pkg/util/tmplutil"
+)
+
+//go:embed templates/roadmap.gotmpl
+var templates embed.FS
+
+// progressBarLength is the length of the progress bar of each milestone
+const progressBarLength = 20
+
+// Manager handles the roadmap across milestones
+type Manager struct {
+	client    *api.RESTClient
+	gqlClient *api.GraphQLClient
+	planner   *planning.Manager
+}
+
+// NewManager creates a new roadmap manager
+func NewManager(client *api.RESTClient, gqlClient *api.GraphQLClient) *Manager {
+	return &Manager{
+		client:    client,
+		gqlClient: gqlClient,
+		planner:   planning.NewManager(client, gqlClient),
+	}
+}
+
+// Options represents roadmap options
+type Options struct {
+	RoadmapLabel string   // Label used to locate the issue where the roadmap will be updated
+	TargetTitle  string   // Title of the roadmap issue
+	Priorities   []string // Labels used to indicate issue priority, ordered from high to low
+	TopItems     int      // Maximum number of top-priority open issues listed for each milestone
+	ExcludePR    bool     // If true, exclude pull requests from the roadmap
+	Pin          bool     // If true, pin the roadmap issue to the repository
+	Template     string   // Path of a custom roadmap template, on local disk or inside the repository
+	Diff         bool     // If true, only show the changes and return diff.ErrChanges if there are any
+	DryRun       bool     // If true, only show preview without making changes
+	AutoConfirm  bool     // If true, skip confirmation prompt
+}
+
+// DefaultOptions returns default roadmap options
+func DefaultOptions() Options {
+	return Options{
+		RoadmapLabel: "roadmap",
+		TargetTitle:  "Roadmap",
+		Priorities:   planning.DefaultOptions().Priorities,
+		TopItems:     5,
+		ExcludePR:    true,
+		Pin:          true,
+		Diff:         false,
+		DryRun:       false,
+		AutoConfirm:  false,
+	}
+}
+
+// MilestoneProgress represents the progress of a milestone on the roadmap
+type MilestoneProgress struct {
+	Milestone       planning.Milestone
+	TotalIssues     int
+	CompletedIssues int
+	ProgressBar     string
+	Overdue         bool
+	TopIssues       []planning.Issue // Open issues with the top two priorities, highest first
+}
+
+// TemplateData represents the data passed to the template
+type TemplateData struct {
+	Milestones []MilestoneProgress // Ordered by due date, milestones without one last
+	Priorities []string
+	RepoOwner  string
+	RepoName   string
+}
+
+// roadmapIssue represents the existing roadmap issue
+type roadmapIssue struct {
+	Title   string `json:"title"`
+	Number  int    `json:"number"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Collect collects the progress of every open milestone of the repository
+func (m *Manager) Collect(ctx context.Context, owner, repo string, opts Options) (TemplateData, error) {
+	data := TemplateData{Priorities: opts.Priorities, RepoOwner: owner, RepoName: repo}
+
+	milestones, err := m.planner.ListOpenMilestones(ctx, owner, repo)
+	if err != nil {
+		return data, err
+	}
+	log.Debug("Found %d open milestones", len(milestones))
+
+	now := time.Now()
+	for _, milestone := range milestones {
+		issues, err := m.planner.ListMilestoneIssues(ctx, owner, repo, milestone.Number, opts.ExcludePR)
+		if err != nil {
+			return data, fmt.Errorf("failed to get issues of milestone '%s': %w", milestone.Title, err)
+		}
+		data.Milestones = append(data.Milestones, newMilestoneProgress(milestone, issues, opts, now))
+	}
+	sortMilestones(data.Milestones)
+
+	return data, nil
+}
+
+// Update updates or creates the roadmap issue
+func (m *Manager) Update(ctx context.Context, owner, repo string, opts Options) error {
+	log.Debug("Updating roadmap issue in %s/%s", owner, repo)
+
+	data, err := m.Collect(ctx, owner, repo, opts)
+	if err != nil {
+		return err
+	}
+
+	// Load custom template if specified
+	var tmplText string
+	if opts.Template != "" {
+		tmplText, err = tmplutil.Load(m.client, fmt.Sprintf("%s/%s", owner, repo), opts.Template)
+		if err != nil {
+			return fmt.Errorf("failed to load roadmap template: %w", err)
+		}
+	}
+
+	// Generate roadmap content
+	content, err := generateContent(data, tmplText)
+	if err != nil {
+		return fmt.Errorf("failed to generate roadmap content: %w", err)
+	}
+	log.Debug("Generated roadmap content with %d bytes", len(content))
+
+	// Find the existing roadmap issue
+	existing, err := m.findIssue(owner, repo, opts.RoadmapLabel, opts.TargetTitle)
+	if err != nil {
+		return err
+	}
+
+	// Create or update the roadmap issue
+	target := issueutil.Target{
+		Kind:  "roadmap issue",
+		Title: opts.TargetTitle,
+		Save: func(content string) (*issueutil.Issue, error) {
+			result, err := m.saveIssue(owner, repo, opts.RoadmapLabel, existing, opts.TargetTitle, content)
+			if err != nil {
+				return nil, err
+			}
+			return &issueutil.Issue{Number: result.Number, Title: result.Title, Body: result.Body, URL: result.HTMLURL}, nil
+		},
+	}
+	if existing != nil {
+		target.Existing = &issueutil.Issue{Number: existing.Number, Title: existing.Title, Body: existing.Body, URL: existing.HTMLURL}
+	}
+	issue, err := issueutil.Update(target, content, issueutil.Options{DryRun: opts.DryRun, AutoConfirm: opts.AutoConfirm, Diff: opts.Diff})
+	if err != nil {
+		return err
+	}
+
+	// Keep the roadmap pinned, even when its content is up to date, which may
+	// fail if the repository already has the maximum number of pinned issues
+	if opts.Pin && issue != nil && !opts.DryRun && !opts.Diff {
+		if err := m.pinIssue(ctx, owner, repo, issue.Number); err != nil {
+			log.Warn("Failed to pin roadmap issue #%d: %v", issue.Number, err)
+		}
+	}
+
+	return nil
+}
+
+// newMilestoneProgress computes the progress, overdue status and top-priority
+// open issues of a milestone
+func newMilestoneProgress(milestone planning.Milestone, issues []planning.Issue, opts Options, now time.Time) MilestoneProgress {
+	progress := MilestoneProgress{
+		Milestone:   milestone,
+		TotalIssues: len(issues),
+	}
+
+	for _, issue := range issues {
+		if issue.State == "closed" {
+			progress.CompletedIssues++
+			continue
+		}
+		// Only include top 2 priority levels
+		if planning.GetPriorityLevel(issue.Labels, opts.Priorities) < min(2, len(opts.Priorities)) {
+			progress.TopIssues = append(progress.TopIssues, issue)
+		}
+	}
+	progress.ProgressBar = planning.GenerateProgressBar(progress.CompletedIssues, progress.TotalIssues, progressBarLength)
+	progress.Overdue = milestone.DueOn != nil && milestone.DueOn.Before(now) && progress.CompletedIssues < progress.TotalIssues
+
+	// Sort top issues by priority, then by issue number
+	sort.Slice(progress.TopIssues, func(i, j int) bool {
+		iPriority := planning.GetPriorityLevel(progress.TopIssues[i].Labels, opts.Priorities)
+		jPriority := planning.GetPriorityLevel(progress.TopIssues[j].Labels, opts.Priorities)
+		if iPriority != jPriority {
+			return iPriority < jPriority
+		}
+		return progress.TopIssues[i].Number < progress.TopIssues[j].Number
+	})
+	if len(progress.TopIssues) > opts.TopItems {
+		progress.TopIssues = progress.TopIssues[:opts.TopItems]
+	}
+
+	return progress
+}
+
+// sortMilestones sorts milestones by due date, putting milestones without a
+// due date last, ordered by number
+func sortMilestones(milestones []MilestoneProgress) {
+	sort.SliceStable(milestones, func(i, j int) bool {
+		di, dj := milestones[i].Milestone.DueOn, milestones[j].Milestone.DueOn
+		switch {
+		case di != nil && dj != nil && !di.Equal(*dj):
+			return di.Before(*dj)
+		case di != nil && dj == nil:
+			return true
+		case di == nil && dj != nil:
+			return false
+		default:
+			return milestones[i].Milestone.Number < milestones[j].Milestone.Number
+		}
+	})
+}
+
+// findIssue returns the oldest issue with the label and title, or nil if there is none
+func (m *Manager) findIssue(owner, repo, label, title string) (*roadmapIssue, error) {
+	var issues []roadmapIssue
+	for page := 1; ; page++ {
+		var pageIssues []roadmapIssue
+		path := fmt.Sprintf("repos/%s/%s/issues?labels=%s&state=all&page=%d&per_page=100", owner, repo, url.QueryEscape(label), page)
+		if err := m.client.Get(path, &pageIssues); err != nil {
+			return nil, fmt.Errorf("failed to get existing roadmap issues: %w", err)
+		}
+		issues = append(issues, pageIssues...)
+
+		// If we got less than per_page items, we've reached the end
+		if len(pageIssues) < 100 {
+			break
+		}
+	}
+	log.Debug("Found %d existing issues with roadmap label", len(issues))
+
+	var found *roadmapIssue
+	minNumber := math.MaxInt32
+	for i, issue := range issues {
+		if issue.Title == title && issue.Number < minNumber {
+			found = &issues[i]
+			minNumber = issue.Number
+		}
+	}
+	return found, nil
+}
+
+// saveIssue creates the roadmap issue, or updates the existing one
+func (m *Manager) saveIssue(owner, repo, label string, existing *roadmapIssue, title, content string) (*roadmapIssue, error) {
+	body := map[string]interface{}{
+		"title": title,
+		"body":  content,
+	}
+	if existing == nil {
+		body["labels"] = []string{label}
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	var response roadmapIssue
+	if existing == nil {
+		path := fmt.Sprintf("repos/%s/%s/issues", owner, repo)
+		if err := m.client.Post(path, bytes.NewReader(bodyBytes), &response); err != nil {
+			return nil, fmt.Errorf("failed to create roadmap issue: %w", err)
+		}
+	} else {
+		path := fmt.Sprintf("repos/%s/%s/issues/%d", owner, repo, existing.Number)
+		if err := m.client.Patch(path, bytes.NewReader(bodyBytes), &response); err != nil {
+			return nil, fmt.Errorf("failed to update roadmap issue: %w", err)
+		}
+	}
+	return &response, nil
+}
+
+// pinIssue pins the issue to the repository unless it is pinned already
+func (m *Manager) pinIssue(ctx context.Context, owner, repo string, number int) error {
+	query := `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) { id isPinned }
+  }
+}`
+	var response struct {
+		Repository struct {
+			Issue struct {
+				ID       string `json:"id"`
+				IsPinned bool   `json:"isPinned"`
+			} `json:"issue"`
+		} `json:"repository"`
+	}
+	variables := map[string]interface{}{"owner": owner, "name": repo, "number": number}
+	if err := m.gqlClient.DoWithContext(ctx, query, variables, &response); err != nil {
+		return fmt.Errorf("failed to get issue: %w", err)
+	}
+	if response.Repository.Issue.IsPinned {
+		return nil
+	}
+
+	mutation := `mutation($input: PinIssueInput!) {
+  pinIssue(input: $input) { issue { number } }
+}`
+	var pinned struct {
+		PinIssue json.RawMessage `json:"pinIssue"`
+	}
+	input := map[string]interface{}{"issueId": response.Repository.Issue.ID}
+	if err := m.gqlClient.DoWithContext(ctx, mutation, map[string]interface{}{"input": input}, &pinned); err != nil {
+		return err
+	}
+	log.Success("Pinned roadmap issue #%d", number)
+	return nil
+}
+
+// generateContent generates the roadmap content using the template. If tmplText
+// is empty, the built-in template is used. The content carries a hidden hash
+// marker computed over the content rendered at a fixed time.
+func generateContent(data TemplateData, tmplText string) (string, error) {
+	content, err := generateContentWithTime(data, tmplText, time.Now())
+	if err != nil {
+		return "", err
+	}
+	stable, err := generateContentWithTime(data, tmplText, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	return hashutil.Mark(content, hashutil.Sum(stable)), nil
+}
+
+// generateContentWithTime generates the roadmap content using the template with a fixed time
+func generateContentWithTime(data TemplateData, tmplText string, now time.Time) (string, error) {
+	funcMap := template.FuncMap{
+		"now": func() string {
+			return now.UTC().Format("January 2, 2006 15:04 MST")
+		},
+		"formatDate": func(date *time.Time) string {
+			if date == nil {
+				return "No due date"
+			}
+			return date.Format("January 2, 2006")
+		},
+		"sub": func(a, b int) int {
+			return a - b
+		},
+		"urlEncode": url.QueryEscape,
+		"getPriorityMark": func(labels []planning.Label) string {
+			level := planning.GetPriorityLevel(labels, data.Priorities)
+			if level >= len(data.Priorities) {
+				return ""
+			}
+			return strings.Repeat("!", len(data.Priorities)-level)
+		},
+	}
+
+	// Load template with functions
+	tmpl := template.New("roadmap.gotmpl").Funcs(funcMap)
+	var err error
+	if tmplText == "" {
+		tmpl, err = tmpl.ParseFS(templates, "templates/roadmap.gotmpl")
+	} else {
+		tmpl, err = tmpl.Parse(tmplText)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// Check the template against the template data before rendering
+	if err := tmplutil.Validate(tmpl, data); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	// Execute template
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
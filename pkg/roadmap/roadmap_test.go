@@ -0,0 +1,201 @@
+package roadmap
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/planning"
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectTransport sends the requests to the GitHub API to a test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newIssue(number int, state string, labels ...string) planning.Issue {
+	issue := planning.Issue{Number: number, State: state}
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, planning.Label{Name: label})
+	}
+	return issue
+}
+
+func TestNewMilestoneProgress(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	past := now.AddDate(0, 0, -1)
+	future := now.AddDate(0, 0, 1)
+	issues := []planning.Issue{
+		newIssue(1, "closed", "priority/high"),
+		newIssue(2, "open", "priority/medium"),
+		newIssue(3, "open", "priority/low"),
+		newIssue(4, "open", "priority/high"),
+		newIssue(5, "open"),
+	}
+	opts := DefaultOptions()
+
+	progress := newMilestoneProgress(planning.Milestone{Title: "v1.0.0", DueOn: &past}, issues, opts, now)
+	assert.Equal(t, 5, progress.TotalIssues)
+	assert.Equal(t, 1, progress.CompletedIssues)
+	assert.Equal(t, "████░░░░░░░░░░░░░░░░ 20%", progress.ProgressBar)
+	assert.True(t, progress.Overdue)
+	numbers := make([]int, 0, len(progress.TopIssues))
+	for _, issue := range progress.TopIssues {
+		numbers = append(numbers, issue.Number)
+	}
+	assert.Equal(t, []int{4, 2}, numbers)
+
+	// Not overdue before the due date or without one
+	assert.False(t, newMilestoneProgress(planning.Milestone{DueOn: &future}, issues, opts, now).Overdue)
+	assert.False(t, newMilestoneProgress(planning.Milestone{}, issues, opts, now).Overdue)
+
+	// Top issues are limited
+	opts.TopItems = 1
+	assert.Len(t, newMilestoneProgress(planning.Milestone{}, issues, opts, now).TopIssues, 1)
+}
+
+func TestSortMilestones(t *testing.T) {
+	early := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	milestones := []MilestoneProgress{
+		{Milestone: planning.Milestone{Number: 4}},
+		{Milestone: planning.Milestone{Number: 3, DueOn: &late}},
+		{Milestone: planning.Milestone{Number: 2}},
+		{Milestone: planning.Milestone{Number: 1, DueOn: &early}},
+	}
+
+	sortMilestones(milestones)
+
+	numbers := make([]int, 0, len(milestones))
+	for _, m := range milestones {
+		numbers = append(numbers, m.Milestone.Number)
+	}
+	assert.Equal(t, []int{1, 3, 2, 4}, numbers)
+}
+
+func TestGenerateContent(t *testing.T) {
+	due := time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)
+	data := TemplateData{
+		Milestones: []MilestoneProgress{
+			{
+				Milestone:       planning.Milestone{Title: "v1.0.0", Number: 1, DueOn: &due, HTMLURL: "https://github.com/owner/repo/milestone/1"},
+				TotalIssues:     4,
+				CompletedIssues: 1,
+				ProgressBar:     "█████░░░░░░░░░░░░░░░ 25%",
+				Overdue:         true,
+				TopIssues:       []planning.Issue{{Number: 7, Labels: []planning.Label{{Name: "priority/high"}}, Assignee: &planning.User{Login: "alice"}}},
+			},
+			{
+				Milestone:   planning.Milestone{Title: "v2.0.0", Number: 2, HTMLURL: "https://github.com/owner/repo/milestone/2"},
+				ProgressBar: "░░░░░░░░░░░░░░░░░░░░ 0%",
+			},
+		},
+		Priorities: planning.DefaultOptions().Priorities,
+		RepoOwner:  "owner",
+		RepoName:   "repo",
+	}
+
+	content, err := generateContentWithTime(data, "", time.Time{})
+	assert.NoError(t, err)
+	assert.Contains(t, content, "## [v1.0.0](https://github.com/owner/repo/milestone/1) ⚠️ Overdue\n")
+	assert.Contains(t, content, "- Progress: █████░░░░░░░░░░░░░░░ 25% (1/4)\n")
+	assert.Contains(t, content, "- Due Date: February 28, 2025\n")
+	assert.Contains(t, content, "- [ ] !!! #7 (@alice)\n")
+	assert.Contains(t, content, "## [v2.0.0](https://github.com/owner/repo/milestone/2)\n")
+	assert.Contains(t, content, "- Due Date: No due date\n")
+	assert.NotContains(t, content, "No open milestones")
+
+	// The hash only changes with the roadmap
+	marked, err := generateContent(data, "")
+	assert.NoError(t, err)
+	other, err := generateContent(data, "")
+	assert.NoError(t, err)
+	assert.True(t, hashutil.Match(marked, other))
+
+	_, err = generateContent(data, "{{ range .Milestones }}{{ .Missing }}{{ end }}")
+	assert.Error(t, err)
+}
+
+func TestUpdatePinsUpToDateIssue(t *testing.T) {
+	data := TemplateData{Priorities: DefaultOptions().Priorities, RepoOwner: "owner", RepoName: "repo"}
+	content, err := generateContent(data, "")
+	require.NoError(t, err)
+
+	var pinned bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/milestones"):
+			_, _ = w.Write([]byte(`[]`))
+		case strings.HasSuffix(r.URL.Path, "/issues"):
+			issues := []roadmapIssue{{Title: "Roadmap", Number: 3, Body: content}}
+			_ = json.NewEncoder(w).Encode(issues)
+		case strings.HasSuffix(r.URL.Path, "/graphql"):
+			query, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(query), "pinIssue") {
+				pinned = true
+				_, _ = w.Write([]byte(`{"data": {"pinIssue": {"issue": {"number": 3}}}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": {"repository": {"issue": {"id": "I_3", "isPinned": false}}}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	clientOpts := api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: redirectTransport{target: target}}
+	client, err := api.NewRESTClient(clientOpts)
+	require.NoError(t, err)
+	gqlClient, err := api.NewGraphQLClient(clientOpts)
+	require.NoError(t, err)
+
+	// The issue is up to date, but it is pinned again
+	err = NewManager(client, gqlClient).Update(context.Background(), "owner", "repo", DefaultOptions())
+	require.NoError(t, err)
+	assert.True(t, pinned)
+}
+
+func TestFindIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var issues []roadmapIssue
+		switch r.URL.Query().Get("page") {
+		case "1":
+			for i := 0; i < 100; i++ {
+				issues = append(issues, roadmapIssue{Title: "Other", Number: 200 - i})
+			}
+		case "2":
+			issues = append(issues, roadmapIssue{Title: "Roadmap", Number: 12}, roadmapIssue{Title: "Roadmap", Number: 5})
+		}
+		_ = json.NewEncoder(w).Encode(issues)
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	client, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: redirectTransport{target: target}})
+	require.NoError(t, err)
+
+	// The oldest issue with the title is found on the second page
+	issue, err := NewManager(client, nil).findIssue("owner", "repo", "roadmap", "Roadmap")
+	require.NoError(t, err)
+	require.NotNil(t, issue)
+	assert.Equal(t, 5, issue.Number)
+}
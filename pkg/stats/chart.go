@@ -0,0 +1,219 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// Interval represents the period that star history is grouped by
+type Interval string
+
+const (
+	// IntervalDay groups star history by calendar day
+	IntervalDay Interval = "day"
+	// IntervalWeek groups star history by week, starting on Monday
+	IntervalWeek Interval = "week"
+	// IntervalMonth groups star history by calendar month
+	IntervalMonth Interval = "month"
+)
+
+const (
+	// chartHeight is the number of rows of the plot area
+	chartHeight = 10
+	// maxColumnWidth is the maximum number of columns used for a single date
+	maxColumnWidth = 3
+	// minChartWidth is the minimum width of the chart, used for narrow terminals
+	minChartWidth = 40
+)
+
+// blocks are the characters used to draw one eighth to a full row of a column
+var blocks = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// ParseInterval parses an interval name
+func ParseInterval(s string) (Interval, error) {
+	switch interval := Interval(strings.ToLower(s)); interval {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return interval, nil
+	default:
+		return "", fmt.Errorf("invalid interval '%s', must be one of: day, week, month", s)
+	}
+}
+
+// start returns the start of the period of the interval that t falls in
+func (i Interval) start(t time.Time) time.Time {
+	t = truncateDay(t)
+	switch i {
+	case IntervalWeek:
+		// Weeks start on Monday
+		return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t
+	}
+}
+
+// dateLayout returns the layout used to label the dates of the interval
+func (i Interval) dateLayout() string {
+	if i == IntervalMonth {
+		return "2006-01"
+	}
+	return "2006-01-02"
+}
+
+// GroupStarHistory groups daily star history by interval. Each period reports
+// the count at its end and the stars gained during the period.
+func GroupStarHistory(history []StarHistory, interval Interval) []StarHistory {
+	var grouped []StarHistory
+	for _, h := range history {
+		start := interval.start(h.Date)
+		if n := len(grouped); n > 0 && grouped[n-1].Date.Equal(start) {
+			grouped[n-1].Stars = h.Stars
+			grouped[n-1].Delta += h.Delta
+			continue
+		}
+		grouped = append(grouped, StarHistory{Date: start, Stars: h.Stars, Delta: h.Delta})
+	}
+	return grouped
+}
+
+// RenderStarChart renders star history as a chart fitting in width columns,
+// with the cumulative stars plotted above the stars gained in each period
+func RenderStarChart(history []StarHistory, interval Interval, width int) []string {
+	if len(history) == 0 {
+		return nil
+	}
+	width = max(width, minChartWidth)
+
+	// Scale the plot between the lowest and highest counts so that growth is
+	// visible for repositories with many stars
+	low, high := history[0].Stars, history[0].Stars
+	for _, h := range history {
+		low = min(low, h.Stars)
+		high = max(high, h.Stars)
+	}
+	if low == high {
+		low = 0
+	}
+
+	labels := []string{formatCount(high), formatCount((low + high) / 2), formatCount(low)}
+	labelWidth := 0
+	for _, label := range labels {
+		labelWidth = max(labelWidth, len(label))
+	}
+
+	// Fit the dates in the plot area, merging adjacent dates if there are too many
+	columns := compressStarHistory(history, width-labelWidth-2)
+	maxDelta := 0
+	for _, c := range columns {
+		maxDelta = max(maxDelta, c.Delta)
+	}
+	deltaLabel := "+" + formatCount(maxDelta)
+	if len(deltaLabel) > labelWidth {
+		labelWidth = len(deltaLabel)
+		columns = compressStarHistory(history, width-labelWidth-2)
+	}
+	columnWidth := max(1, min(maxColumnWidth, (width-labelWidth-2)/len(columns)))
+
+	var lines []string
+	for row := chartHeight - 1; row >= 0; row-- {
+		label := ""
+		switch row {
+		case chartHeight - 1:
+			label = labels[0]
+		case chartHeight / 2:
+			label = labels[1]
+		case 0:
+			label = labels[2]
+		}
+		axis := "│"
+		if label != "" {
+			axis = "┤"
+		}
+
+		var plot strings.Builder
+		for _, c := range columns {
+			eighths := 0
+			if high > low {
+				eighths = (c.Stars - low) * chartHeight * 8 / (high - low)
+			}
+			if c.Stars > 0 {
+				// Any star is drawn at least one eighth high
+				eighths = max(eighths, 1)
+			}
+			block := blocks[min(max(eighths-row*8, 0), 8)]
+			plot.WriteString(strings.Repeat(string(block), columnWidth))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s%s",
+			log.Colorize(log.ColorGray, fmt.Sprintf("%*s", labelWidth, label)),
+			log.Colorize(log.ColorGray, axis),
+			log.Colorize(log.ColorCyan, plot.String())))
+	}
+
+	// Draw the date axis with the first and last dates
+	plotWidth := len(columns) * columnWidth
+	indent := strings.Repeat(" ", labelWidth+1)
+	lines = append(lines, indent+log.Colorize(log.ColorGray, "└"+strings.Repeat("─", plotWidth)))
+	layout := interval.dateLayout()
+	first, last := columns[0].Date.Format(layout), columns[len(columns)-1].Date.Format(layout)
+	dates := first
+	if gap := plotWidth - len(first) - len(last); len(columns) > 1 && gap > 0 {
+		dates += strings.Repeat(" ", gap) + last
+	}
+	lines = append(lines, indent+" "+log.Colorize(log.ColorGray, dates))
+
+	// Draw the stars gained in each period as a sparkline
+	var sparkline strings.Builder
+	for _, c := range columns {
+		level := 0
+		if maxDelta > 0 && c.Delta > 0 {
+			// Any gain is drawn at least one eighth high
+			level = max(1, c.Delta*8/maxDelta)
+		}
+		sparkline.WriteString(strings.Repeat(string(blocks[level]), columnWidth))
+	}
+	lines = append(lines, fmt.Sprintf("%s %s%s",
+		log.Colorize(log.ColorGray, fmt.Sprintf("%*s", labelWidth, deltaLabel)),
+		log.Colorize(log.ColorGray, "┤"),
+		log.Colorize(log.ColorGreen, sparkline.String())))
+
+	return lines
+}
+
+// compressStarHistory merges adjacent dates so that there are at most n of them.
+// Merged dates report the date and count of the first and last date respectively,
+// and the total stars gained.
+func compressStarHistory(history []StarHistory, n int) []StarHistory {
+	n = max(n, 1)
+	if len(history) <= n {
+		return history
+	}
+
+	compressed := make([]StarHistory, 0, n)
+	for i := 0; i < n; i++ {
+		group := history[i*len(history)/n : (i+1)*len(history)/n]
+		merged := StarHistory{Date: group[0].Date, Stars: group[len(group)-1].Stars}
+		for _, h := range group {
+			merged.Delta += h.Delta
+		}
+		compressed = append(compressed, merged)
+	}
+	return compressed
+}
+
+// formatCount formats a count compactly, e.g. 1234 as 1.2k
+func formatCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1_000_000), ".0") + "M"
+	case n >= 10_000:
+		return fmt.Sprintf("%dk", n/1000)
+	case n >= 1000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000), ".0") + "k"
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
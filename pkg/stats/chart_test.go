@@ -0,0 +1,74 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupStarHistory(t *testing.T) {
+	// 2025-01-01 is a Wednesday
+	var history []StarHistory
+	for d := 1; d <= 40; d++ {
+		history = append(history, StarHistory{
+			Date:  time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC),
+			Stars: d,
+			Delta: 1,
+		})
+	}
+
+	weeks := GroupStarHistory(history, IntervalWeek)
+	assert.Equal(t, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), weeks[0].Date)
+	assert.Equal(t, StarHistory{Date: weeks[1].Date, Stars: 12, Delta: 7}, weeks[1])
+
+	months := GroupStarHistory(history, IntervalMonth)
+	assert.Equal(t, []StarHistory{
+		{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Stars: 31, Delta: 31},
+		{Date: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Stars: 40, Delta: 9},
+	}, months)
+}
+
+func TestParseInterval(t *testing.T) {
+	interval, err := ParseInterval("Week")
+	assert.NoError(t, err)
+	assert.Equal(t, IntervalWeek, interval)
+
+	_, err = ParseInterval("year")
+	assert.Error(t, err)
+}
+
+func TestRenderStarChart(t *testing.T) {
+	log.SetNoColor(true)
+	defer log.SetNoColor(false)
+
+	var history []StarHistory
+	for d := 0; d < 365; d++ {
+		history = append(history, StarHistory{
+			Date:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, d),
+			Stars: 1000 + d*10,
+			Delta: 10,
+		})
+	}
+
+	lines := RenderStarChart(history, IntervalDay, 60)
+	assert.Len(t, lines, chartHeight+3)
+	for _, line := range lines {
+		assert.NotContains(t, line, "\033[")
+		assert.LessOrEqual(t, utf8.RuneCountInString(line), 60)
+	}
+	assert.True(t, strings.HasPrefix(lines[0], "4.6k ┤"))
+	assert.True(t, strings.HasPrefix(lines[chartHeight-1], "  1k ┤"))
+	assert.Contains(t, lines[chartHeight+1], "2024-01-01")
+	assert.Contains(t, lines[chartHeight+2], "+70 ┤")
+}
+
+func TestFormatCount(t *testing.T) {
+	assert.Equal(t, "999", formatCount(999))
+	assert.Equal(t, "1.2k", formatCount(1234))
+	assert.Equal(t, "12k", formatCount(12345))
+	assert.Equal(t, "2M", formatCount(2_000_000))
+}
@@ -0,0 +1,279 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/auth"
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+const (
+	// maxCommitPages is the maximum number of pages of commits listed for contributor analytics
+	maxCommitPages = 50
+
+	// maxSearchPages is the number of pages the GitHub search API returns at most
+	maxSearchPages = 10
+
+	// busFactorShare is the share of contributions that the bus factor contributors account for
+	busFactorShare = 0.5
+)
+
+// ContributorOptions represents the options of contributor analytics
+type ContributorOptions struct {
+	Days int // Number of days of the period, the previous period of the same length is used for comparison
+	Top  int // Number of top contributors used to compute the concentration of contributions
+}
+
+// DefaultContributorOptions returns default contributor options
+func DefaultContributorOptions() ContributorOptions {
+	return ContributorOptions{
+		Days: 90,
+		Top:  5,
+	}
+}
+
+// Contributor represents the contributions of a user in a period
+type Contributor struct {
+	Login     string `json:"login"`
+	Commits   int    `json:"commits"`
+	MergedPRs int    `json:"merged_prs"`
+	FirstTime bool   `json:"first_time"` // true if the user had never contributed before the period
+}
+
+// Contributions returns the number of commits and merged pull requests
+func (c *Contributor) Contributions() int {
+	return c.Commits + c.MergedPRs
+}
+
+// ContributorReport represents the contributors of a repository in a period
+type ContributorReport struct {
+	Repository       string        `json:"repository"`
+	From             time.Time     `json:"from"`
+	To               time.Time     `json:"to"`
+	Contributors     []Contributor `json:"contributors"` // sorted by contributions
+	New              int           `json:"new"`
+	Returning        int           `json:"returning"`
+	BusFactor        int           `json:"bus_factor"` // fewest contributors accounting for half of the contributions
+	Top              int           `json:"top"`
+	TopShare         float64       `json:"top_share"`         // share of contributions of the top contributors
+	Lost             []Contributor `json:"lost"`              // contributors of the previous period inactive in this one
+	PreviousExcluded bool          `json:"previous_excluded"` // true if the previous period had too many contributions to compare with
+}
+
+// GetContributors returns the contributors of the commits and merged pull requests
+// of the period, compared with the previous period of the same length. The
+// previous period is excluded from the comparison if it has too many
+// contributions to list them all.
+func (m *Manager) GetContributors(ctx context.Context, repoName string, opts ContributorOptions) (*ContributorReport, error) {
+	// Get token
+	token, err := auth.GetToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	from := truncateDay(now.AddDate(0, 0, -opts.Days))
+	previousFrom := from.AddDate(0, 0, -opts.Days)
+
+	// Collect the contributions of both periods
+	current, complete, err := m.listContributions(ctx, token, repoName, from, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if !complete {
+		return nil, fmt.Errorf("too many contributions in the last %d days to analyze them all, use fewer days", opts.Days)
+	}
+	previous, complete, err := m.listContributions(ctx, token, repoName, previousFrom, from)
+	if err != nil {
+		return nil, err
+	}
+	if !complete {
+		log.Warn("Too many contributions in the previous %d days to list them all, excluding them from the comparison", opts.Days)
+		previous = nil
+		previousFrom = from
+	}
+
+	// Check whether the new faces of the period have contributed before
+	contributedBefore := make(map[string]bool)
+	for login := range current {
+		if _, ok := previous[login]; ok {
+			continue
+		}
+		before, err := m.hasCommitsBefore(ctx, token, repoName, login, previousFrom)
+		if err != nil {
+			return nil, err
+		}
+		contributedBefore[login] = before
+	}
+
+	report := buildContributorReport(current, previous, contributedBefore, opts.Top)
+	report.Repository = repoName
+	report.From = from
+	report.To = now
+	report.PreviousExcluded = previous == nil
+	return report, nil
+}
+
+// listContributions returns the contributors of the commits committed and the pull
+// requests merged from since until until, or up to now if until is zero. It also
+// reports whether all of them could be listed.
+func (m *Manager) listContributions(ctx context.Context, token, repoName string, since, until time.Time) (map[string]*Contributor, bool, error) {
+	contributors := make(map[string]*Contributor)
+	contributor := func(login string) *Contributor {
+		c, ok := contributors[login]
+		if !ok {
+			c = &Contributor{Login: login}
+			contributors[login] = c
+		}
+		return c
+	}
+
+	commitsComplete, err := m.listCommits(ctx, token, repoName, since, until, func(login string) {
+		contributor(login).Commits++
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	prsComplete, err := m.listMergedPRs(ctx, token, repoName, since, until, func(login string) {
+		contributor(login).MergedPRs++
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return contributors, commitsComplete && prsComplete, nil
+}
+
+// listCommits calls fn with the author login of each commit committed from since
+// until until, or up to now if until is zero, skipping bots and commits not linked
+// to a GitHub user. It reports whether all commits could be listed.
+func (m *Manager) listCommits(ctx context.Context, token, repoName string, since, until time.Time, fn func(login string)) (bool, error) {
+	params := "since=" + since.Format(time.RFC3339)
+	if !until.IsZero() {
+		params += "&until=" + until.Format(time.RFC3339)
+	}
+	for page := 1; page <= maxCommitPages; page++ {
+		var commits []struct {
+			Author *ghUser `json:"author"`
+		}
+		url := fmt.Sprintf("https://api.github.com/repos/%s/commits?%s&per_page=%d&page=%d", repoName, params, listPerPage, page)
+		if err := m.getJSON(ctx, token, url, "application/vnd.github.v3+json", &commits); err != nil {
+			return false, fmt.Errorf("failed to list commits: %w", err)
+		}
+		for _, commit := range commits {
+			if commit.Author == nil || commit.Author.isBot() {
+				continue
+			}
+			fn(commit.Author.Login)
+		}
+		log.Debug("Got %d commits from page %d", len(commits), page)
+		if len(commits) < listPerPage {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listMergedPRs calls fn with the author login of each pull request merged from
+// since until until, or up to now if until is zero, skipping bots. It reports
+// whether all pull requests could be listed.
+func (m *Manager) listMergedPRs(ctx context.Context, token, repoName string, since, until time.Time, fn func(login string)) (bool, error) {
+	query := fmt.Sprintf("repo:%s type:pr is:merged merged:>=%s", repoName, since.Format("2006-01-02"))
+	if !until.IsZero() {
+		query += fmt.Sprintf(" merged:<%s", until.Format("2006-01-02"))
+	}
+	for page := 1; page <= maxSearchPages; page++ {
+		var result struct {
+			TotalCount int `json:"total_count"`
+			Items      []struct {
+				User ghUser `json:"user"`
+			} `json:"items"`
+		}
+		searchURL := fmt.Sprintf("https://api.github.com/search/issues?q=%s&per_page=%d&page=%d", url.QueryEscape(query), listPerPage, page)
+		if err := m.getJSON(ctx, token, searchURL, "application/vnd.github.v3+json", &result); err != nil {
+			return false, fmt.Errorf("failed to search merged pull requests: %w", err)
+		}
+		if result.TotalCount > maxSearchPages*listPerPage {
+			return false, nil
+		}
+		for _, item := range result.Items {
+			if item.User.isBot() {
+				continue
+			}
+			fn(item.User.Login)
+		}
+		log.Debug("Got %d merged pull requests from page %d", len(result.Items), page)
+		if len(result.Items) < listPerPage {
+			return true, nil
+		}
+	}
+	return true, nil
+}
+
+// hasCommitsBefore reports whether the user authored any commit before the time
+func (m *Manager) hasCommitsBefore(ctx context.Context, token, repoName, login string, before time.Time) (bool, error) {
+	var commits []struct{}
+	commitsURL := fmt.Sprintf("https://api.github.com/repos/%s/commits?author=%s&until=%s&per_page=1",
+		repoName, url.QueryEscape(login), before.Format(time.RFC3339))
+	if err := m.getJSON(ctx, token, commitsURL, "application/vnd.github.v3+json", &commits); err != nil {
+		return false, fmt.Errorf("failed to list commits of %s: %w", login, err)
+	}
+	return len(commits) > 0, nil
+}
+
+// buildContributorReport compares the contributors of the current and previous
+// periods. contributedBefore tells whether contributors absent from the previous
+// period contributed earlier.
+func buildContributorReport(current, previous map[string]*Contributor, contributedBefore map[string]bool, top int) *ContributorReport {
+	report := &ContributorReport{Top: top}
+
+	total := 0
+	for login, c := range current {
+		if _, ok := previous[login]; ok || contributedBefore[login] {
+			report.Returning++
+		} else {
+			c.FirstTime = true
+			report.New++
+		}
+		total += c.Contributions()
+		report.Contributors = append(report.Contributors, *c)
+	}
+	sortContributors(report.Contributors)
+
+	for login, c := range previous {
+		if _, ok := current[login]; !ok {
+			report.Lost = append(report.Lost, *c)
+		}
+	}
+	sortContributors(report.Lost)
+
+	// Compute the concentration of contributions
+	if total > 0 {
+		sum := 0
+		for i, c := range report.Contributors {
+			sum += c.Contributions()
+			if i < top {
+				report.TopShare = float64(sum) / float64(total)
+			}
+			if report.BusFactor == 0 && float64(sum) >= busFactorShare*float64(total) {
+				report.BusFactor = i + 1
+			}
+		}
+	}
+
+	return report
+}
+
+// sortContributors sorts contributors by contributions in descending order, then by login
+func sortContributors(contributors []Contributor) {
+	sort.Slice(contributors, func(i, j int) bool {
+		ci, cj := contributors[i].Contributions(), contributors[j].Contributions()
+		if ci != cj {
+			return ci > cj
+		}
+		return contributors[i].Login < contributors[j].Login
+	})
+}
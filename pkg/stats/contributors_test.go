@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildContributorReport(t *testing.T) {
+	current := map[string]*Contributor{
+		"alice": {Login: "alice", Commits: 6, MergedPRs: 2},
+		"bob":   {Login: "bob", Commits: 3},
+		"carol": {Login: "carol", MergedPRs: 1},
+		"dave":  {Login: "dave", Commits: 1},
+	}
+	previous := map[string]*Contributor{
+		"alice": {Login: "alice", Commits: 4},
+		"erin":  {Login: "erin", Commits: 2},
+	}
+	contributedBefore := map[string]bool{"bob": true}
+
+	report := buildContributorReport(current, previous, contributedBefore, 2)
+
+	logins := make([]string, 0, len(report.Contributors))
+	for _, c := range report.Contributors {
+		logins = append(logins, c.Login)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave"}, logins)
+	assert.Equal(t, 2, report.New)
+	assert.Equal(t, 2, report.Returning)
+	assert.False(t, report.Contributors[1].FirstTime)
+	assert.True(t, report.Contributors[2].FirstTime)
+
+	// alice alone accounts for 8 of 13 contributions
+	assert.Equal(t, 1, report.BusFactor)
+	assert.InDelta(t, 11.0/13.0, report.TopShare, 1e-9)
+
+	assert.Equal(t, []Contributor{{Login: "erin", Commits: 2}}, report.Lost)
+}
+
+func TestListContributions(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	tooManyPRs := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/commits":
+			// Commits are bucketed by the committer date they are filtered on
+			assert.Equal(t, "2025-01-01T00:00:00Z", r.URL.Query().Get("since"))
+			assert.Equal(t, "2025-04-01T00:00:00Z", r.URL.Query().Get("until"))
+			_, _ = w.Write([]byte(`[{"author": {"login": "alice"}}, {"author": {"login": "ci[bot]", "type": "Bot"}}, {"author": null}]`))
+		case "/search/issues":
+			assert.Equal(t, "repo:owner/repo type:pr is:merged merged:>=2025-01-01 merged:<2025-04-01", r.URL.Query().Get("q"))
+			if tooManyPRs {
+				_, _ = w.Write([]byte(`{"total_count": 1001, "items": []}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"total_count": 2, "items": [{"user": {"login": "alice"}}, {"user": {"login": "bob"}}]}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	m := &Manager{client: &http.Client{Transport: redirectTransport{target: target}}}
+
+	contributors, complete, err := m.listContributions(context.Background(), "token", "owner/repo", since, until)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, map[string]*Contributor{
+		"alice": {Login: "alice", Commits: 1, MergedPRs: 1},
+		"bob":   {Login: "bob", MergedPRs: 1},
+	}, contributors)
+
+	// A period with more pull requests than the search API returns is incomplete
+	tooManyPRs = true
+	_, complete, err = m.listContributions(context.Background(), "token", "owner/repo", since, until)
+	require.NoError(t, err)
+	assert.False(t, complete)
+}
@@ -0,0 +1,117 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// ActivityDays is the number of days that recent activity is counted over
+const ActivityDays = 30
+
+// errNotFound is returned when the requested GitHub resource does not exist
+var errNotFound = errors.New("not found")
+
+// lastPagePattern matches the page number of the last page in a Link header
+var lastPagePattern = regexp.MustCompile(`<[^>]*[?&]page=(\d+)[^>]*>;\s*rel="last"`)
+
+// getHealth fills in the health metrics of a repository
+func (m *Manager) getHealth(ctx context.Context, token, repoName string, stats *Stats) error {
+	since := time.Now().UTC().AddDate(0, 0, -ActivityDays)
+
+	// The open issue count of the repository includes pull requests
+	openPRs, err := m.searchCount(ctx, token, fmt.Sprintf("repo:%s type:pr state:open", repoName))
+	if err != nil {
+		return fmt.Errorf("failed to count open pull requests: %w", err)
+	}
+	stats.OpenPRs = openPRs
+	stats.OpenIssues = max(stats.OpenIssues-openPRs, 0)
+
+	mergedPRs, err := m.searchCount(ctx, token, fmt.Sprintf("repo:%s type:pr is:merged", repoName))
+	if err != nil {
+		return fmt.Errorf("failed to count merged pull requests: %w", err)
+	}
+	stats.MergedPRs = mergedPRs
+
+	recentMergedPRs, err := m.searchCount(ctx, token, fmt.Sprintf("repo:%s type:pr merged:>=%s", repoName, since.Format("2006-01-02")))
+	if err != nil {
+		return fmt.Errorf("failed to count merged pull requests: %w", err)
+	}
+	stats.RecentMergedPRs = recentMergedPRs
+
+	contributors, err := m.listCount(ctx, token, fmt.Sprintf("https://api.github.com/repos/%s/contributors?anon=1", repoName))
+	if err != nil {
+		return fmt.Errorf("failed to count contributors: %w", err)
+	}
+	stats.Contributors = contributors
+
+	commits, err := m.listCount(ctx, token, fmt.Sprintf("https://api.github.com/repos/%s/commits?since=%s", repoName, since.Format(time.RFC3339)))
+	if err != nil {
+		return fmt.Errorf("failed to count commits: %w", err)
+	}
+	stats.RecentCommits = commits
+
+	var release struct {
+		TagName     string    `json:"tag_name"`
+		PublishedAt time.Time `json:"published_at"`
+	}
+	err = m.getJSON(ctx, token, fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repoName), "application/vnd.github.v3+json", &release)
+	switch {
+	case errors.Is(err, errNotFound):
+		log.Debug("No release found for %s", repoName)
+	case err != nil:
+		return fmt.Errorf("failed to get latest release: %w", err)
+	default:
+		stats.LatestRelease = release.TagName
+		stats.LatestReleaseAt = &release.PublishedAt
+	}
+
+	return nil
+}
+
+// searchCount returns the number of issues and pull requests matching the search query
+func (m *Manager) searchCount(ctx context.Context, token, query string) (int, error) {
+	var result struct {
+		TotalCount int `json:"total_count"`
+	}
+	searchURL := fmt.Sprintf("https://api.github.com/search/issues?q=%s&per_page=1", url.QueryEscape(query))
+	if err := m.getJSON(ctx, token, searchURL, "application/vnd.github.v3+json", &result); err != nil {
+		return 0, err
+	}
+	return result.TotalCount, nil
+}
+
+// listCount returns the number of items of a list endpoint by requesting a
+// single item per page and reading the number of the last page
+func (m *Manager) listCount(ctx context.Context, token, listURL string) (int, error) {
+	var items []json.RawMessage
+	header, err := m.get(ctx, token, listURL+"&per_page=1", "application/vnd.github.v3+json", &items)
+	if err != nil {
+		return 0, err
+	}
+	if count, ok := lastPage(header); ok {
+		return count, nil
+	}
+	return len(items), nil
+}
+
+// lastPage returns the number of the last page from the Link header
+func lastPage(header http.Header) (int, bool) {
+	match := lastPagePattern.FindStringSubmatch(header.Get("Link"))
+	if match == nil {
+		return 0, false
+	}
+	page, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return page, true
+}
@@ -0,0 +1,20 @@
+package stats
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastPage(t *testing.T) {
+	header := http.Header{}
+	header.Set("Link", `<https://api.github.com/repositories/1/contributors?anon=1&per_page=1&page=2>; rel="next", `+
+		`<https://api.github.com/repositories/1/contributors?anon=1&per_page=1&page=42>; rel="last"`)
+	page, ok := lastPage(header)
+	assert.True(t, ok)
+	assert.Equal(t, 42, page)
+
+	_, ok = lastPage(http.Header{})
+	assert.False(t, ok)
+}
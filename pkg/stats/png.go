@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+// pngScale is the pixel density of the PNG chart, so that it stays sharp on
+// high resolution screens when displayed at the SVG size
+const pngScale = 2
+
+// font5x7 is a 5x7 pixel font for the printable ASCII characters starting at
+// the space, with an extra row for descenders. Each glyph is 5 columns, the
+// lowest bit of a column being its top row.
+var font5x7 = [...][5]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00}, {0x00, 0x00, 0x5F, 0x00, 0x00}, {0x00, 0x07, 0x00, 0x07, 0x00}, {0x14, 0x7F, 0x14, 0x7F, 0x14}, // space ! " #
+	{0x24, 0x2A, 0x7F, 0x2A, 0x12}, {0x23, 0x13, 0x08, 0x64, 0x62}, {0x36, 0x49, 0x55, 0x22, 0x50}, {0x00, 0x05, 0x03, 0x00, 0x00}, // $ % & '
+	{0x00, 0x1C, 0x22, 0x41, 0x00}, {0x00, 0x41, 0x22, 0x1C, 0x00}, {0x08, 0x2A, 0x1C, 0x2A, 0x08}, {0x08, 0x08, 0x3E, 0x08, 0x08}, // ( ) * +
+	{0x00, 0x50, 0x30, 0x00, 0x00}, {0x08, 0x08, 0x08, 0x08, 0x08}, {0x00, 0x60, 0x60, 0x00, 0x00}, {0x20, 0x10, 0x08, 0x04, 0x02}, // , - . /
+	{0x3E, 0x51, 0x49, 0x45, 0x3E}, {0x00, 0x42, 0x7F, 0x40, 0x00}, {0x42, 0x61, 0x51, 0x49, 0x46}, {0x21, 0x41, 0x45, 0x4B, 0x31}, // 0 1 2 3
+	{0x18, 0x14, 0x12, 0x7F, 0x10}, {0x27, 0x45, 0x45, 0x45, 0x39}, {0x3C, 0x4A, 0x49, 0x49, 0x30}, {0x01, 0x71, 0x09, 0x05, 0x03}, // 4 5 6 7
+	{0x36, 0x49, 0x49, 0x49, 0x36}, {0x06, 0x49, 0x49, 0x29, 0x1E}, {0x00, 0x36, 0x36, 0x00, 0x00}, {0x00, 0x56, 0x36, 0x00, 0x00}, // 8 9 : ;
+	{0x08, 0x14, 0x22, 0x41, 0x00}, {0x14, 0x14, 0x14, 0x14, 0x14}, {0x00, 0x41, 0x22, 0x14, 0x08}, {0x02, 0x01, 0x51, 0x09, 0x06}, // < = > ?
+	{0x32, 0x49, 0x79, 0x41, 0x3E}, {0x7E, 0x11, 0x11, 0x11, 0x7E}, {0x7F, 0x49, 0x49, 0x49, 0x36}, {0x3E, 0x41, 0x41, 0x41, 0x22}, // @ A B C
+	{0x7F, 0x41, 0x41, 0x22, 0x1C}, {0x7F, 0x49, 0x49, 0x49, 0x41}, {0x7F, 0x09, 0x09, 0x09, 0x01}, {0x3E, 0x41, 0x49, 0x49, 0x7A}, // D E F G
+	{0x7F, 0x08, 0x08, 0x08, 0x7F}, {0x00, 0x41, 0x7F, 0x41, 0x00}, {0x20, 0x40, 0x41, 0x3F, 0x01}, {0x7F, 0x08, 0x14, 0x22, 0x41}, // H I J K
+	{0x7F, 0x40, 0x40, 0x40, 0x40}, {0x7F, 0x02, 0x0C, 0x02, 0x7F}, {0x7F, 0x04, 0x08, 0x10, 0x7F}, {0x3E, 0x41, 0x41, 0x41, 0x3E}, // L M N O
+	{0x7F, 0x09, 0x09, 0x09, 0x06}, {0x3E, 0x41, 0x51, 0x21, 0x5E}, {0x7F, 0x09, 0x19, 0x29, 0x46}, {0x46, 0x49, 0x49, 0x49, 0x31}, // P Q R S
+	{0x01, 0x01, 0x7F, 0x01, 0x01}, {0x3F, 0x40, 0x40, 0x40, 0x3F}, {0x1F, 0x20, 0x40, 0x20, 0x1F}, {0x3F, 0x40, 0x38, 0x40, 0x3F}, // T U V W
+	{0x63, 0x14, 0x08, 0x14, 0x63}, {0x07, 0x08, 0x70, 0x08, 0x07}, {0x61, 0x51, 0x49, 0x45, 0x43}, {0x00, 0x7F, 0x41, 0x41, 0x00}, // X Y Z [
+	{0x02, 0x04, 0x08, 0x10, 0x20}, {0x00, 0x41, 0x41, 0x7F, 0x00}, {0x04, 0x02, 0x01, 0x02, 0x04}, {0x40, 0x40, 0x40, 0x40, 0x40}, // \ ] ^ _
+	{0x00, 0x01, 0x02, 0x04, 0x00}, {0x20, 0x54, 0x54, 0x54, 0x78}, {0x7F, 0x48, 0x44, 0x44, 0x38}, {0x38, 0x44, 0x44, 0x44, 0x20}, // ` a b c
+	{0x38, 0x44, 0x44, 0x48, 0x7F}, {0x38, 0x54, 0x54, 0x54, 0x18}, {0x08, 0x7E, 0x09, 0x01, 0x02}, {0x18, 0xA4, 0xA4, 0xA4, 0x7C}, // d e f g
+	{0x7F, 0x08, 0x04, 0x04, 0x78}, {0x00, 0x44, 0x7D, 0x40, 0x00}, {0x40, 0x80, 0x84, 0x7D, 0x00}, {0x7F, 0x10, 0x28, 0x44, 0x00}, // h i j k
+	{0x00, 0x41, 0x7F, 0x40, 0x00}, {0x7C, 0x04, 0x18, 0x04, 0x78}, {0x7C, 0x08, 0x04, 0x04, 0x78}, {0x38, 0x44, 0x44, 0x44, 0x38}, // l m n o
+	{0xFC, 0x24, 0x24, 0x24, 0x18}, {0x18, 0x24, 0x24, 0x18, 0xFC}, {0x7C, 0x08, 0x04, 0x04, 0x08}, {0x48, 0x54, 0x54, 0x54, 0x20}, // p q r s
+	{0x04, 0x3F, 0x44, 0x40, 0x20}, {0x3C, 0x40, 0x40, 0x20, 0x7C}, {0x1C, 0x20, 0x40, 0x20, 0x1C}, {0x3C, 0x40, 0x30, 0x40, 0x3C}, // t u v w
+	{0x44, 0x28, 0x10, 0x28, 0x44}, {0x1C, 0xA0, 0xA0, 0xA0, 0x7C}, {0x44, 0x64, 0x54, 0x4C, 0x44}, {0x00, 0x08, 0x36, 0x41, 0x00}, // x y z {
+	{0x00, 0x00, 0x7F, 0x00, 0x00}, {0x00, 0x41, 0x36, 0x08, 0x00}, {0x08, 0x04, 0x08, 0x10, 0x08}, // | } ~
+}
+
+// pngCanvas draws the star history chart on an image, in the coordinates of
+// the SVG chart
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+// RenderStarHistoryPNG renders the star history of one or more repositories as
+// a PNG line chart with the same layout as RenderStarHistorySVG
+func RenderStarHistoryPNG(series []StarSeries, width, height int) ([]byte, error) {
+	l := newChartLayout(series, width, height)
+	c := pngCanvas{img: image.NewRGBA(image.Rect(0, 0, l.width*pngScale, l.height*pngScale))}
+	c.fillRect(0, 0, float64(l.width), float64(l.height), "#ffffff")
+	c.text(svgMarginLeft, 28, "Star History", 3, "#24292f", 0)
+
+	// Draw the star axis with grid lines
+	for stars := 0; stars <= l.top; stars += l.step {
+		c.line(svgMarginLeft, l.y(stars), svgMarginLeft+l.plotWidth, l.y(stars), 1, "#d0d7de")
+		c.text(svgMarginLeft-8, l.y(stars)+4, formatCount(stars), 2, "#57606a", 1)
+	}
+
+	// Draw the date axis with evenly spaced dates
+	for _, date := range l.dates() {
+		c.text(l.x(date), svgMarginTop+l.plotHeight+20, date.Format(l.dateLayout), 2, "#57606a", 0.5)
+	}
+	c.line(svgMarginLeft, svgMarginTop+l.plotHeight, svgMarginLeft+l.plotWidth, svgMarginTop+l.plotHeight, 1, "#57606a")
+
+	// Draw a line and a legend entry for each repository
+	for i, s := range series {
+		color := svgColors[i%len(svgColors)]
+		for j := 1; j < len(s.History); j++ {
+			prev, cur := s.History[j-1], s.History[j]
+			c.line(l.x(prev.Date), l.y(prev.Stars), l.x(cur.Date), l.y(cur.Stars), 2, color)
+		}
+
+		legendY := float64(svgMarginTop + 10 + i*18)
+		c.fillRect(svgMarginLeft+10, legendY-10, 12, 12, color)
+		c.text(svgMarginLeft+28, legendY, s.Repository, 2, "#24292f", 0)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fillRect fills a rectangle
+func (c pngCanvas) fillRect(x, y, width, height float64, hex string) {
+	fill := parseHexColor(hex)
+	x0, y0 := int(math.Round(x*pngScale)), int(math.Round(y*pngScale))
+	x1, y1 := int(math.Round((x+width)*pngScale)), int(math.Round((y+height)*pngScale))
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			c.img.SetRGBA(px, py, fill)
+		}
+	}
+}
+
+// line draws a straight line of the given stroke width
+func (c pngCanvas) line(x0, y0, x1, y1, strokeWidth float64, hex string) {
+	stroke := parseHexColor(hex)
+	radius := strokeWidth * pngScale / 2
+	x0, y0, x1, y1 = x0*pngScale, y0*pngScale, x1*pngScale, y1*pngScale
+	steps := int(math.Ceil(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)) * 2))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		cx, cy := x0+(x1-x0)*t, y0+(y1-y0)*t
+		for py := int(math.Floor(cy - radius)); py < int(math.Ceil(cy+radius)); py++ {
+			for px := int(math.Floor(cx - radius)); px < int(math.Ceil(cx+radius)); px++ {
+				if dx, dy := float64(px)+0.5-cx, float64(py)+0.5-cy; dx*dx+dy*dy <= radius*radius {
+					c.img.SetRGBA(px, py, stroke)
+				}
+			}
+		}
+	}
+}
+
+// text draws text with its baseline at y, each font pixel being pixel pixels of
+// the image. The text is aligned on x by anchor: 0 at its start, 0.5 at its
+// middle and 1 at its end.
+func (c pngCanvas) text(x, y float64, s string, pixel int, hex string, anchor float64) {
+	fill := parseHexColor(hex)
+	advance := 6 * pixel
+	left := int(math.Round(x*pngScale - anchor*float64(len(s)*advance-pixel)))
+	top := int(math.Round(y*pngScale)) - 7*pixel
+	for i, r := range []byte(s) {
+		if r < ' ' || int(r-' ') >= len(font5x7) {
+			r = '?'
+		}
+		glyph := font5x7[r-' ']
+		for col, bits := range glyph {
+			for row := 0; row < 8; row++ {
+				if bits&(1<<row) == 0 {
+					continue
+				}
+				px, py := left+i*advance+col*pixel, top+row*pixel
+				for dy := 0; dy < pixel; dy++ {
+					for dx := 0; dx < pixel; dx++ {
+						c.img.SetRGBA(px+dx, py+dy, fill)
+					}
+				}
+			}
+		}
+	}
+}
+
+// parseHexColor parses a color in #rrggbb format
+func parseHexColor(hex string) color.RGBA {
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
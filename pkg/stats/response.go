@@ -0,0 +1,338 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/auth"
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+const (
+	// listPerPage is the page size used when listing issues and comments
+	listPerPage = 100
+
+	// maxIssuePages is the maximum number of pages of issues listed for response analytics
+	maxIssuePages = 20
+
+	// maxCommentPages is the maximum number of pages of comments listed for response analytics
+	maxCommentPages = 50
+)
+
+// ResponseOptions represents the options of response time analytics
+type ResponseOptions struct {
+	Days               int  // Number of days of issues and pull requests to analyze
+	ExcludeMaintainers bool // If true, skip issues and pull requests opened by maintainers, responses by maintainers still count
+}
+
+// DefaultResponseOptions returns default response time options
+func DefaultResponseOptions() ResponseOptions {
+	return ResponseOptions{
+		Days:               90,
+		ExcludeMaintainers: false,
+	}
+}
+
+// ResponseStats represents the response times of a set of issues or pull requests,
+// durations are in hours
+type ResponseStats struct {
+	Count               int     `json:"count"`
+	Responded           int     `json:"responded"`
+	Closed              int     `json:"closed"`
+	FirstResponseMedian float64 `json:"first_response_median_hours"`
+	FirstResponseP90    float64 `json:"first_response_p90_hours"`
+	CloseMedian         float64 `json:"close_median_hours"`
+	CloseP90            float64 `json:"close_p90_hours"`
+}
+
+// MonthlyResponse represents the response times of the issues and pull requests
+// opened in a month
+type MonthlyResponse struct {
+	Month        string        `json:"month"` // in YYYY-MM format
+	Issues       ResponseStats `json:"issues"`
+	PullRequests ResponseStats `json:"pull_requests"`
+}
+
+// ResponseReport represents the response times of a repository over a window
+type ResponseReport struct {
+	Repository   string            `json:"repository"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Issues       ResponseStats     `json:"issues"`
+	PullRequests ResponseStats     `json:"pull_requests"`
+	Months       []MonthlyResponse `json:"months"`
+}
+
+// ghUser represents the author of a GitHub issue or comment
+type ghUser struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// isBot reports whether the user is a bot account
+func (u ghUser) isBot() bool {
+	return u.Type == "Bot" || strings.HasSuffix(u.Login, "[bot]")
+}
+
+// ghIssue represents a GitHub issue or pull request as listed by the issues API
+type ghIssue struct {
+	Number            int        `json:"number"`
+	User              ghUser     `json:"user"`
+	AuthorAssociation string     `json:"author_association"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ClosedAt          *time.Time `json:"closed_at"`
+	PullRequest       *struct{}  `json:"pull_request"`
+}
+
+// isMaintainerAssociation reports whether the author association belongs to a maintainer
+func isMaintainerAssociation(association string) bool {
+	switch association {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return true
+	default:
+		return false
+	}
+}
+
+// responseItem represents an issue or pull request with its response times
+type responseItem struct {
+	Number          int
+	IsPR            bool
+	CreatedAt       time.Time
+	ClosedAt        *time.Time
+	FirstResponseAt *time.Time
+}
+
+// GetResponseTimes returns the time to first response and the time to close of
+// the issues and pull requests opened in the window. Responses are comments,
+// review comments and reviews by anyone but the author, excluding bots.
+func (m *Manager) GetResponseTimes(ctx context.Context, repoName string, opts ResponseOptions) (*ResponseReport, error) {
+	// Get token
+	token, err := auth.GetToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	from := truncateDay(now.AddDate(0, 0, -opts.Days))
+
+	// List issues and pull requests opened in the window
+	var issues []ghIssue
+	for page := 1; page <= maxIssuePages; page++ {
+		var pageIssues []ghIssue
+		url := fmt.Sprintf("https://api.github.com/repos/%s/issues?state=all&sort=created&direction=desc&per_page=%d&page=%d", repoName, listPerPage, page)
+		if err := m.getJSON(ctx, token, url, "application/vnd.github.v3+json", &pageIssues); err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		done := len(pageIssues) < listPerPage
+		for _, issue := range pageIssues {
+			if issue.CreatedAt.Before(from) {
+				done = true
+				break
+			}
+			issues = append(issues, issue)
+		}
+		log.Debug("Got %d issues and pull requests from page %d", len(pageIssues), page)
+		if done {
+			break
+		}
+		if page == maxIssuePages {
+			log.Warn("Only the latest %d issues and pull requests are analyzed", len(issues))
+		}
+	}
+
+	// Skip bots and optionally maintainers, and remember the authors to tell responses apart
+	items := make(map[int]*responseItem)
+	authors := make(map[int]string)
+	for _, issue := range issues {
+		if issue.User.isBot() || (opts.ExcludeMaintainers && isMaintainerAssociation(issue.AuthorAssociation)) {
+			continue
+		}
+		items[issue.Number] = &responseItem{
+			Number:    issue.Number,
+			IsPR:      issue.PullRequest != nil,
+			CreatedAt: issue.CreatedAt,
+			ClosedAt:  issue.ClosedAt,
+		}
+		authors[issue.Number] = issue.User.Login
+	}
+
+	// Find the first response from the comments and review comments made in the window
+	respond := func(number int, user ghUser, createdAt time.Time) {
+		if item, ok := items[number]; ok {
+			item.recordResponse(authors[number], user, createdAt)
+		}
+	}
+	for _, kind := range []string{"issues", "pulls"} {
+		if err := m.listComments(ctx, token, repoName, kind, from, respond); err != nil {
+			return nil, err
+		}
+	}
+
+	// Reviews without inline comments, such as approvals, are responses too but
+	// can only be listed for each pull request
+	for _, item := range items {
+		if !item.IsPR {
+			continue
+		}
+		if err := m.listReviews(ctx, token, repoName, item.Number, respond); err != nil {
+			return nil, err
+		}
+	}
+
+	list := make([]responseItem, 0, len(items))
+	for _, item := range items {
+		list = append(list, *item)
+	}
+	report := buildResponseReport(list)
+	report.Repository = repoName
+	report.From = from
+	report.To = now
+	return report, nil
+}
+
+// recordResponse records a comment or review of the user as the first response
+// if it is earlier, ignoring bots and the author
+func (item *responseItem) recordResponse(author string, user ghUser, createdAt time.Time) {
+	if user.isBot() || user.Login == author {
+		return
+	}
+	if item.FirstResponseAt == nil || createdAt.Before(*item.FirstResponseAt) {
+		item.FirstResponseAt = &createdAt
+	}
+}
+
+// listReviews calls fn with each submitted review of a pull request
+func (m *Manager) listReviews(ctx context.Context, token, repoName string, number int, fn func(number int, user ghUser, createdAt time.Time)) error {
+	var reviews []struct {
+		User        ghUser     `json:"user"`
+		SubmittedAt *time.Time `json:"submitted_at"`
+	}
+	url := fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d/reviews?per_page=%d", repoName, number, listPerPage)
+	if err := m.getJSON(ctx, token, url, "application/vnd.github.v3+json", &reviews); err != nil {
+		return fmt.Errorf("failed to list reviews of pull request #%d: %w", number, err)
+	}
+	for _, review := range reviews {
+		// Pending reviews are not submitted yet
+		if review.SubmittedAt != nil {
+			fn(number, review.User, *review.SubmittedAt)
+		}
+	}
+	return nil
+}
+
+// listComments calls fn with each issue comment or pull request review comment
+// created since from, depending on kind
+func (m *Manager) listComments(ctx context.Context, token, repoName, kind string, from time.Time, fn func(number int, user ghUser, createdAt time.Time)) error {
+	for page := 1; page <= maxCommentPages; page++ {
+		var comments []struct {
+			User      ghUser    `json:"user"`
+			CreatedAt time.Time `json:"created_at"`
+			IssueURL  string    `json:"issue_url"`
+			PullURL   string    `json:"pull_request_url"`
+		}
+		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/comments?since=%s&sort=created&direction=asc&per_page=%d&page=%d",
+			repoName, kind, from.Format(time.RFC3339), listPerPage, page)
+		if err := m.getJSON(ctx, token, url, "application/vnd.github.v3+json", &comments); err != nil {
+			return fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, comment := range comments {
+			parentURL := comment.IssueURL
+			if parentURL == "" {
+				parentURL = comment.PullURL
+			}
+			number, err := strconv.Atoi(parentURL[strings.LastIndex(parentURL, "/")+1:])
+			if err != nil {
+				continue
+			}
+			fn(number, comment.User, comment.CreatedAt)
+		}
+		log.Debug("Got %d %s comments from page %d", len(comments), kind, page)
+		if len(comments) < listPerPage {
+			return nil
+		}
+	}
+	log.Warn("Only the first %d %s comments are analyzed", maxCommentPages*listPerPage, kind)
+	return nil
+}
+
+// buildResponseReport summarizes the response times of the items overall and by
+// the month they were opened in
+func buildResponseReport(items []responseItem) *ResponseReport {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	split := func(items []responseItem) (issues, prs []responseItem) {
+		for _, item := range items {
+			if item.IsPR {
+				prs = append(prs, item)
+			} else {
+				issues = append(issues, item)
+			}
+		}
+		return issues, prs
+	}
+
+	report := &ResponseReport{}
+	issues, prs := split(items)
+	report.Issues = summarizeResponses(issues)
+	report.PullRequests = summarizeResponses(prs)
+
+	for start := 0; start < len(items); {
+		month := items[start].CreatedAt.UTC().Format("2006-01")
+		end := start
+		for end < len(items) && items[end].CreatedAt.UTC().Format("2006-01") == month {
+			end++
+		}
+		issues, prs := split(items[start:end])
+		report.Months = append(report.Months, MonthlyResponse{
+			Month:        month,
+			Issues:       summarizeResponses(issues),
+			PullRequests: summarizeResponses(prs),
+		})
+		start = end
+	}
+
+	return report
+}
+
+// summarizeResponses computes the medians and 90th percentiles of the response times
+func summarizeResponses(items []responseItem) ResponseStats {
+	var responses, closes []float64
+	for _, item := range items {
+		if item.FirstResponseAt != nil {
+			responses = append(responses, item.FirstResponseAt.Sub(item.CreatedAt).Hours())
+		}
+		if item.ClosedAt != nil {
+			closes = append(closes, item.ClosedAt.Sub(item.CreatedAt).Hours())
+		}
+	}
+
+	return ResponseStats{
+		Count:               len(items),
+		Responded:           len(responses),
+		Closed:              len(closes),
+		FirstResponseMedian: percentile(responses, 50),
+		FirstResponseP90:    percentile(responses, 90),
+		CloseMedian:         percentile(closes, 50),
+		CloseP90:            percentile(closes, 90),
+	}
+}
+
+// percentile returns the p-th percentile of values using the nearest rank method,
+// or zero if there are no values
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
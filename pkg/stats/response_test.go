@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildResponseReport(t *testing.T) {
+	at := func(month time.Month, day, hour int) *time.Time {
+		t := time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	items := []responseItem{
+		{Number: 1, CreatedAt: *at(1, 1, 0), FirstResponseAt: at(1, 1, 2), ClosedAt: at(1, 2, 0)},
+		{Number: 2, CreatedAt: *at(1, 10, 0), FirstResponseAt: at(1, 10, 4)},
+		{Number: 3, CreatedAt: *at(2, 1, 0)},
+		{Number: 4, IsPR: true, CreatedAt: *at(2, 3, 0), FirstResponseAt: at(2, 3, 1), ClosedAt: at(2, 3, 12)},
+	}
+
+	report := buildResponseReport(items)
+	assert.Equal(t, ResponseStats{
+		Count:               3,
+		Responded:           2,
+		Closed:              1,
+		FirstResponseMedian: 2,
+		FirstResponseP90:    4,
+		CloseMedian:         24,
+		CloseP90:            24,
+	}, report.Issues)
+	assert.Equal(t, 1, report.PullRequests.Count)
+	assert.Equal(t, 12.0, report.PullRequests.CloseMedian)
+
+	assert.Len(t, report.Months, 2)
+	assert.Equal(t, "2025-01", report.Months[0].Month)
+	assert.Equal(t, 2, report.Months[0].Issues.Count)
+	assert.Equal(t, 1, report.Months[1].Issues.Count)
+	assert.Equal(t, 1, report.Months[1].PullRequests.Count)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	assert.Equal(t, 5.0, percentile(values, 50))
+	assert.Equal(t, 9.0, percentile(values, 90))
+	assert.Equal(t, 0.0, percentile(nil, 50))
+}
+
+// redirectTransport sends the requests to the GitHub API to a test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestListReviews(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/owner/repo/pulls/4/reviews", r.URL.Path)
+		_, _ = w.Write([]byte(`[
+			{"user": {"login": "pending"}, "state": "PENDING"},
+			{"user": {"login": "maintainer"}, "state": "APPROVED", "submitted_at": "2025-02-03T05:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	m := &Manager{client: &http.Client{Transport: redirectTransport{target: target}}}
+
+	// An approval without comments is the first response of the pull request
+	item := &responseItem{Number: 4, IsPR: true, CreatedAt: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)}
+	err = m.listReviews(context.Background(), "token", "owner/repo", 4, func(_ int, user ghUser, createdAt time.Time) {
+		item.recordResponse("author", user, createdAt)
+	})
+	require.NoError(t, err)
+	require.NotNil(t, item.FirstResponseAt)
+	assert.Equal(t, time.Date(2025, 2, 3, 5, 0, 0, 0, time.UTC), *item.FirstResponseAt)
+}
+
+func TestRecordResponse(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2025, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+	item := &responseItem{Number: 1, CreatedAt: at(0)}
+
+	item.recordResponse("author", ghUser{Login: "author"}, at(1))
+	item.recordResponse("author", ghUser{Login: "ci[bot]"}, at(1))
+	assert.Nil(t, item.FirstResponseAt)
+
+	item.recordResponse("author", ghUser{Login: "maintainer"}, at(5))
+	item.recordResponse("author", ghUser{Login: "reviewer"}, at(3))
+	item.recordResponse("author", ghUser{Login: "other"}, at(4))
+	assert.Equal(t, at(3), *item.FirstResponseAt)
+}
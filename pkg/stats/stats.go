@@ -5,222 +5,478 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/elliotxx/osp/pkg/auth"
 	"github.com/elliotxx/osp/pkg/config"
+	"github.com/elliotxx/osp/pkg/log"
 )
 
 // Manager manages repository statistics
 type Manager struct {
 	state  *config.State
 	client *http.Client
+	store  *Store // nil if the local store is disabled
+	opts   Options
+}
+
+// Options represents the options of the stats manager
+type Options struct {
+	Offline bool // If true, answer queries from the local store without calling the GitHub API
+	NoCache bool // If true, neither read nor update the local store
+}
+
+// DefaultOptions returns default stats options
+func DefaultOptions() Options {
+	return Options{
+		Offline: false,
+		NoCache: false,
+	}
 }
 
 // Stats represents repository statistics
 type Stats struct {
 	Stars       int    `json:"stars"`
 	Forks       int    `json:"forks"`
-	OpenIssues  int    `json:"open_issues"`
+	OpenIssues  int    `json:"open_issues"` // open issues excluding pull requests
 	LastUpdated string `json:"last_updated"`
+
+	Watchers        int        `json:"watchers"`
+	OpenPRs         int        `json:"open_prs"`
+	Contributors    int        `json:"contributors"`
+	LatestRelease   string     `json:"latest_release,omitempty"`
+	LatestReleaseAt *time.Time `json:"latest_release_at,omitempty"`
+	RecentCommits   int        `json:"recent_commits"`    // commits in the last ActivityDays days
+	RecentMergedPRs int        `json:"recent_merged_prs"` // pull requests merged in the last ActivityDays days
+	MergedPRs       int        `json:"merged_prs"`
+}
+
+// ReleaseAge returns the time since the latest release relative to now, or
+// zero if there is no release
+func (s *Stats) ReleaseAge(now time.Time) time.Duration {
+	if s.LatestReleaseAt == nil {
+		return 0
+	}
+	return now.Sub(*s.LatestReleaseAt)
 }
 
 // StarHistory represents star count at a specific date
 type StarHistory struct {
 	Date  time.Time `json:"date"`
 	Stars int       `json:"stars"`
+	Delta int       `json:"delta"` // stars gained since the previous date
 }
 
-// StarEvent represents a GitHub star event
-type StarEvent struct {
-	Type      string    `json:"type"`
-	CreatedAt time.Time `json:"created_at"`
+// Stargazer represents a GitHub stargazer with the time the repository was starred
+type Stargazer struct {
+	StarredAt time.Time `json:"starred_at"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
 }
 
+// key returns the identity of a stargazer used to skip stargazers that are already stored
+func (s Stargazer) key() string {
+	return s.User.Login + "@" + s.StarredAt.UTC().Format(time.RFC3339)
+}
+
+// starPoint represents the cumulative star count at a point in time
+type starPoint struct {
+	At    time.Time
+	Stars int
+}
+
+const (
+	// stargazersPerPage is the page size used when listing stargazers
+	stargazersPerPage = 100
+
+	// maxStargazerPages is the last page GitHub allows to list for stargazers
+	maxStargazerPages = 400
+
+	// maxFullStargazerPages is the number of pages up to which all stargazers are
+	// listed, larger repositories are sampled
+	maxFullStargazerPages = 30
+
+	// stargazerSamples is the number of pages requested when sampling stargazers
+	stargazerSamples = 20
+)
+
 // NewManager creates a new stats manager
-func NewManager() (*Manager, error) {
+func NewManager(opts Options) (*Manager, error) {
+	if opts.Offline && opts.NoCache {
+		return nil, fmt.Errorf("offline mode requires the local store, which is disabled")
+	}
+
 	state, err := config.LoadState()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
 
-	return &Manager{
+	m := &Manager{
 		state:  state,
 		client: &http.Client{},
-	}, nil
+		opts:   opts,
+	}
+	if !opts.NoCache {
+		m.store = NewStore("")
+	}
+
+	return m, nil
 }
 
-// Get returns repository statistics
+// Get returns repository statistics and records them as the snapshot of the day
 func (m *Manager) Get(ctx context.Context, repoName string) (*Stats, error) {
+	// Answer from the latest snapshot in offline mode
+	if m.opts.Offline {
+		data, err := m.store.Load(repoName)
+		if err != nil {
+			return nil, err
+		}
+		snapshot := data.LatestSnapshot()
+		if snapshot == nil {
+			return nil, fmt.Errorf("no statistics stored for %s, run without --offline first", repoName)
+		}
+		log.Debug("Using statistics snapshot of %s", snapshot.Date)
+		return &snapshot.Stats, nil
+	}
+
 	// Get token
 	token, err := auth.GetToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
 
-	// Create request
+	// Get repository
+	var data struct {
+		Stars      int       `json:"stargazers_count"`
+		Forks      int       `json:"forks_count"`
+		OpenIssues int       `json:"open_issues_count"`
+		Watchers   int       `json:"subscribers_count"`
+		UpdatedAt  string    `json:"updated_at"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
 	url := fmt.Sprintf("https://api.github.com/repos/%s", repoName)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if err := m.getJSON(ctx, token, url, "application/vnd.github.v3+json", &data); err != nil {
+		return nil, err
 	}
 
-	// Add headers
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	stats := &Stats{
+		Stars:       data.Stars,
+		Forks:       data.Forks,
+		OpenIssues:  data.OpenIssues,
+		LastUpdated: data.UpdatedAt,
+		Watchers:    data.Watchers,
+	}
 
-	// Send request
-	resp, err := m.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	// Get health metrics
+	if err := m.getHealth(ctx, token, repoName, stats); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	// Record the snapshot of the day
+	if m.store != nil {
+		if err := m.updateStore(repoName, func(stored *RepoData) error {
+			stored.CreatedAt = data.CreatedAt
+			stored.AddSnapshot(time.Now(), *stats)
+			return nil
+		}); err != nil {
+			log.Warn("Failed to record statistics snapshot: %v", err)
+		}
 	}
 
-	// Parse response
-	var data struct {
-		Stars      int    `json:"stargazers_count"`
-		Forks      int    `json:"forks_count"`
-		OpenIssues int    `json:"open_issues_count"`
-		UpdatedAt  string `json:"updated_at"`
+	return stats, nil
+}
+
+// GetHistory returns the locally stored daily snapshots of the specified number of
+// days, or all snapshots if days is not positive. It never calls the GitHub API.
+func (m *Manager) GetHistory(repoName string, days int) ([]Snapshot, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("statistics history requires the local store, which is disabled")
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+
+	data, err := m.store.Load(repoName)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Stats{
-		Stars:       data.Stars,
-		Forks:       data.Forks,
-		OpenIssues:  data.OpenIssues,
-		LastUpdated: data.UpdatedAt,
-	}, nil
+	if days <= 0 {
+		return data.Snapshots, nil
+	}
+	from := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+	var snapshots []Snapshot
+	for _, snapshot := range data.Snapshots {
+		if snapshot.Date >= from {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
 }
 
-// GetStarHistory returns star history for the specified number of days
+// GetStarHistory returns the daily cumulative star count for the specified number
+// of days, or since the repository was created if days is not positive.
+//
+// Stargazers are stored locally and only new ones are listed on later runs, so
+// the counts are exact for repositories with up to 40,000 stars. For larger
+// repositories, or when the local store is disabled and the repository has too
+// many stars to list them all, stargazer pages are sampled and the counts between
+// the samples are interpolated.
 func (m *Manager) GetStarHistory(ctx context.Context, repoName string, days int) ([]StarHistory, error) {
+	now := time.Now().UTC()
+
+	// Answer from the stored stargazers in offline mode
+	if m.opts.Offline {
+		data, err := m.store.Load(repoName)
+		if err != nil {
+			return nil, err
+		}
+		if len(data.Stargazers) == 0 {
+			return nil, fmt.Errorf("no stargazers stored for %s, run without --offline first", repoName)
+		}
+		from := data.CreatedAt
+		if days > 0 || from.IsZero() {
+			from = now.AddDate(0, 0, -max(days, 0))
+		}
+		return buildStarHistory(stargazerPoints(data.Stargazers), from, now, false), nil
+	}
+
 	// Get token
 	token, err := auth.GetToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
 
-	// Calculate time range
-	now := time.Now()
-	from := now.AddDate(0, 0, -days)
-
-	// Get current stars
-	stats, err := m.Get(ctx, repoName)
-	if err != nil {
-		return nil, err
+	// Get current stars and creation date
+	var repo struct {
+		Stars     int       `json:"stargazers_count"`
+		CreatedAt time.Time `json:"created_at"`
 	}
-	currentStars := stats.Stars
-
-	// Get star events
-	events, err := m.getStarEvents(ctx, repoName, token, from)
-	if err != nil {
+	url := fmt.Sprintf("https://api.github.com/repos/%s", repoName)
+	if err := m.getJSON(ctx, token, url, "application/vnd.github.v3+json", &repo); err != nil {
 		return nil, err
 	}
 
-	// Create daily star counts
-	history := make([]StarHistory, days+1)
-	starsByDate := make(map[string]int)
-
-	// Initialize with current stars
-	for i := 0; i <= days; i++ {
-		date := from.AddDate(0, 0, i)
-		dateStr := date.Format("2006-01-02")
-		starsByDate[dateStr] = currentStars
+	// Calculate time range
+	from := repo.CreatedAt.UTC()
+	if days > 0 {
+		from = now.AddDate(0, 0, -days)
 	}
 
-	// Process star events backwards
-	for _, event := range events {
-		if event.Type == "WatchEvent" { // WatchEvent is GitHub's term for starring
-			// Decrease star count for all dates before this event
-			for i := 0; i <= days; i++ {
-				d := from.AddDate(0, 0, i)
-				if d.Before(event.CreatedAt) {
-					dateStr := d.Format("2006-01-02")
-					starsByDate[dateStr]--
+	// Get star points
+	pages := (repo.Stars + stargazersPerPage - 1) / stargazersPerPage
+	var points []starPoint
+	interpolate := false
+	switch {
+	case m.store != nil && pages <= maxStargazerPages:
+		var stored []Stargazer
+		err = m.updateStore(repoName, func(data *RepoData) error {
+			// Start one page before the first unknown stargazer, since earlier
+			// stargazers may have removed their stars and shifted the pages
+			startPage := max(len(data.Stargazers)/stargazersPerPage, 1)
+			if pages-startPage > maxFullStargazerPages {
+				log.Info("Fetching %d pages of stargazers, this may take a while", pages-startPage+1)
+			}
+
+			stargazers, err := m.listStargazers(ctx, repoName, token, startPage, pages)
+			if err != nil {
+				return err
+			}
+			added := data.MergeStargazers(stargazers)
+			log.Debug("Stored %d new stargazers", added)
+
+			// More stored stargazers than current stars means some users removed
+			// their stars, which the incremental listing cannot see
+			if len(data.Stargazers) > repo.Stars {
+				log.Info("%d stargazers stored for %d stars, listing all stargazers to drop removed stars", len(data.Stargazers), repo.Stars)
+				current, err := m.listStargazers(ctx, repoName, token, 1, pages)
+				if err != nil {
+					return err
 				}
+				removed := data.ReconcileStargazers(current)
+				log.Debug("Removed %d stargazers who unstarred", removed)
 			}
-		}
+			data.CreatedAt = repo.CreatedAt
+			stored = data.Stargazers
+			return nil
+		})
+		points = stargazerPoints(stored)
+	case pages > maxFullStargazerPages:
+		interpolate = true
+		points, err = m.sampleStargazers(ctx, repoName, token, pages)
+	default:
+		var stargazers []Stargazer
+		stargazers, err = m.listStargazers(ctx, repoName, token, 1, pages)
+		points = stargazerPoints(stargazers)
 	}
-
-	// Convert map to slice
-	for i := 0; i <= days; i++ {
-		date := from.AddDate(0, 0, i)
-		dateStr := date.Format("2006-01-02")
-		history[i] = StarHistory{
-			Date:  date,
-			Stars: starsByDate[dateStr],
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	return history, nil
+	// The latest count is the current one, which also accounts for removed stars
+	points = append(points, starPoint{At: now, Stars: repo.Stars})
+
+	return buildStarHistory(points, from, now, interpolate), nil
 }
 
-// getStarEvents returns star events for a repository
-func (m *Manager) getStarEvents(ctx context.Context, repoName, token string, from time.Time) ([]StarEvent, error) {
-	var events []StarEvent
-	page := 1
-	perPage := 100
+// updateStore loads the stored data of a repository, applies fn and saves the result
+// unless fn fails
+func (m *Manager) updateStore(repoName string, fn func(data *RepoData) error) error {
+	data, err := m.store.Load(repoName)
+	if err != nil {
+		return err
+	}
+	if err := fn(data); err != nil {
+		return err
+	}
+	return m.store.Save(repoName, data)
+}
 
-	owner, repo, _ := strings.Cut(repoName, "/")
-	baseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/events", owner, repo)
+// stargazerPoints converts stargazers sorted by time into cumulative star points
+func stargazerPoints(stargazers []Stargazer) []starPoint {
+	points := make([]starPoint, 0, len(stargazers))
+	for i, stargazer := range stargazers {
+		points = append(points, starPoint{At: stargazer.StarredAt, Stars: i + 1})
+	}
+	return points
+}
 
-	for {
-		// Create request
-		url := fmt.Sprintf("%s?page=%d&per_page=%d", baseURL, page, perPage)
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// listStargazers returns the stargazers listed on the pages from startPage to endPage
+func (m *Manager) listStargazers(ctx context.Context, repoName, token string, startPage, endPage int) ([]Stargazer, error) {
+	var stargazers []Stargazer
+	for page := startPage; page <= endPage; page++ {
+		pageStargazers, err := m.getStargazers(ctx, repoName, token, page)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			return nil, err
 		}
+		stargazers = append(stargazers, pageStargazers...)
+		log.Debug("Got %d stargazers from page %d", len(pageStargazers), page)
+		if len(pageStargazers) < stargazersPerPage {
+			break
+		}
+	}
+	return stargazers, nil
+}
 
-		// Add headers
-		req.Header.Set("Accept", "application/vnd.github.v3+json")
-		req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
-
-		// Send request
-		resp, err := m.client.Do(req)
+// sampleStargazers returns star points from evenly spaced stargazer pages,
+// taking the first stargazer of each page
+func (m *Manager) sampleStargazers(ctx context.Context, repoName, token string, pages int) ([]starPoint, error) {
+	lastPage := min(pages, maxStargazerPages)
+	var points []starPoint
+	for i := 0; i < stargazerSamples; i++ {
+		page := 1 + i*(lastPage-1)/(stargazerSamples-1)
+		stargazers, err := m.getStargazers(ctx, repoName, token, page)
 		if err != nil {
-			return nil, fmt.Errorf("failed to send request: %w", err)
+			return nil, err
 		}
-		defer resp.Body.Close()
-
-		// Check status code
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		if len(stargazers) == 0 {
+			break
 		}
+		points = append(points, starPoint{
+			At:    stargazers[0].StarredAt,
+			Stars: (page-1)*stargazersPerPage + 1,
+		})
+	}
+	return points, nil
+}
 
-		// Parse response
-		var pageEvents []StarEvent
-		if err := json.NewDecoder(resp.Body).Decode(&pageEvents); err != nil {
-			return nil, fmt.Errorf("failed to parse response: %w", err)
+// getStargazers returns a page of stargazers with the time they starred the repository
+func (m *Manager) getStargazers(ctx context.Context, repoName, token string, page int) ([]Stargazer, error) {
+	var stargazers []Stargazer
+	url := fmt.Sprintf("https://api.github.com/repos/%s/stargazers?page=%d&per_page=%d", repoName, page, stargazersPerPage)
+	// The star media type includes the starred_at timestamps
+	if err := m.getJSON(ctx, token, url, "application/vnd.github.star+json", &stargazers); err != nil {
+		return nil, fmt.Errorf("failed to get stargazers: %w", err)
+	}
+	return stargazers, nil
+}
+
+// buildStarHistory converts star points sorted by time into daily star counts
+// from the start of the day of from to the day of to. Each day reports the count
+// at its end and the stars gained during the day. If interpolate is true, counts
+// between points are linearly interpolated, otherwise the count of the latest
+// preceding point is used.
+func buildStarHistory(points []starPoint, from, to time.Time, interpolate bool) []StarHistory {
+	from = truncateDay(from)
+	to = truncateDay(to)
+
+	next := 0 // index of the first point at or after the current time
+	starsAt := func(t time.Time) int {
+		for next < len(points) && points[next].At.Before(t) {
+			next++
 		}
 
-		// Check if we've reached events before our cutoff date
-		reachedEnd := false
-		for _, event := range pageEvents {
-			if event.CreatedAt.Before(from) {
-				reachedEnd = true
-				break
-			}
-			if event.Type == "WatchEvent" {
-				events = append(events, event)
+		stars := 0
+		if next > 0 {
+			stars = points[next-1].Stars
+		}
+		if interpolate && next > 0 && next < len(points) {
+			prev, succ := points[next-1], points[next]
+			if span := succ.At.Sub(prev.At); span > 0 {
+				ratio := float64(t.Sub(prev.At)) / float64(span)
+				stars += int(ratio * float64(succ.Stars-prev.Stars))
 			}
 		}
+		return stars
+	}
 
-		if reachedEnd || len(pageEvents) < perPage {
-			break
-		}
+	var history []StarHistory
+	prev := starsAt(from)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		stars := starsAt(date.AddDate(0, 0, 1))
+		history = append(history, StarHistory{Date: date, Stars: stars, Delta: stars - prev})
+		prev = stars
+	}
+
+	return history
+}
+
+// truncateDay returns the start of the UTC day of t
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// getJSON sends a GET request to the GitHub API and decodes the response into v
+func (m *Manager) getJSON(ctx context.Context, token, url, accept string, v interface{}) error {
+	_, err := m.get(ctx, token, url, accept, v)
+	return err
+}
+
+// get sends a GET request to the GitHub API, decodes the response into v and
+// returns the response headers
+func (m *Manager) get(ctx context.Context, token, url, accept string, v interface{}) (http.Header, error) {
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add headers
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+
+	// Send request
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
 
-		page++
+	// Check status code
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		// Lists of empty repositories have no content
+		return resp.Header, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Parse response
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return events, nil
+	return resp.Header, nil
 }
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildStarHistory(t *testing.T) {
+	day := func(d, h int) time.Time {
+		return time.Date(2025, 1, d, h, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("exact counts", func(t *testing.T) {
+		points := []starPoint{
+			{At: day(1, 10), Stars: 1},
+			{At: day(1, 12), Stars: 2},
+			{At: day(3, 8), Stars: 3},
+			{At: day(4, 20), Stars: 3},
+		}
+
+		history := buildStarHistory(points, day(1, 9), day(4, 20), false)
+		assert.Equal(t, []StarHistory{
+			{Date: day(1, 0), Stars: 2, Delta: 2},
+			{Date: day(2, 0), Stars: 2, Delta: 0},
+			{Date: day(3, 0), Stars: 3, Delta: 1},
+			{Date: day(4, 0), Stars: 3, Delta: 0},
+		}, history)
+	})
+
+	t.Run("stars before the range are counted", func(t *testing.T) {
+		points := []starPoint{
+			{At: day(1, 10), Stars: 1},
+			{At: day(3, 10), Stars: 2},
+		}
+
+		history := buildStarHistory(points, day(2, 0), day(3, 0), false)
+		assert.Equal(t, []int{1, 2}, []int{history[0].Stars, history[1].Stars})
+		assert.Equal(t, []int{0, 1}, []int{history[0].Delta, history[1].Delta})
+	})
+
+	t.Run("interpolated counts", func(t *testing.T) {
+		points := []starPoint{
+			{At: day(1, 0), Stars: 1},
+			{At: day(5, 0), Stars: 401},
+		}
+
+		history := buildStarHistory(points, day(1, 0), day(5, 0), true)
+		stars := make([]int, 0, len(history))
+		for _, h := range history {
+			stars = append(stars, h.Stars)
+		}
+		assert.Equal(t, []int{101, 201, 301, 401, 401}, stars)
+	})
+}
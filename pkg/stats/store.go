@@ -0,0 +1,165 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elliotxx/osp/pkg/config"
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// StoreDirName is the name of the directory under the data directory where statistics are stored
+const StoreDirName = "stats"
+
+// Snapshot represents the repository statistics recorded on a day
+type Snapshot struct {
+	Date string `json:"date"` // UTC date in YYYY-MM-DD format
+	Stats
+}
+
+// RepoData represents the statistics stored locally for a repository
+type RepoData struct {
+	CreatedAt  time.Time   `json:"created_at,omitempty"`
+	Snapshots  []Snapshot  `json:"snapshots,omitempty"`
+	Stargazers []Stargazer `json:"stargazers,omitempty"`
+}
+
+// Store persists statistics of repositories on local disk, so that history
+// can be updated incrementally and queried offline
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store under the given directory, or under the OSP data
+// directory if dir is empty
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = filepath.Join(config.GetDataDir(), StoreDirName)
+	}
+	return &Store{dir: dir}
+}
+
+// path returns the path of the data file of a repository
+func (s *Store) path(repoName string) string {
+	owner, repo, _ := strings.Cut(repoName, "/")
+	return filepath.Join(s.dir, owner, repo+".json")
+}
+
+// Load loads the stored data of a repository, returning empty data if there is none
+func (s *Store) Load(repoName string) (*RepoData, error) {
+	path := s.path(repoName)
+	log.Debug("Loading statistics from: %s", path)
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &RepoData{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statistics file: %w", err)
+	}
+
+	data := &RepoData{}
+	if err := json.Unmarshal(content, data); err != nil {
+		return nil, fmt.Errorf("failed to parse statistics file: %w", err)
+	}
+	return data, nil
+}
+
+// Save saves the data of a repository
+func (s *Store) Save(repoName string, data *RepoData) error {
+	path := s.path(repoName)
+	log.Debug("Saving statistics to: %s", path)
+
+	if err := os.MkdirAll(filepath.Dir(path), config.DefaultDirMode); err != nil {
+		return fmt.Errorf("failed to create statistics directory: %w", err)
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal statistics: %w", err)
+	}
+
+	// Write to a temporary file first so that an interrupted write does not corrupt the history
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, config.DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write statistics file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write statistics file: %w", err)
+	}
+
+	return nil
+}
+
+// AddSnapshot records the statistics of the day, replacing an earlier snapshot of the same day
+func (d *RepoData) AddSnapshot(date time.Time, stats Stats) {
+	day := date.UTC().Format("2006-01-02")
+	for i := range d.Snapshots {
+		if d.Snapshots[i].Date == day {
+			d.Snapshots[i].Stats = stats
+			return
+		}
+	}
+	d.Snapshots = append(d.Snapshots, Snapshot{Date: day, Stats: stats})
+	sort.Slice(d.Snapshots, func(i, j int) bool {
+		return d.Snapshots[i].Date < d.Snapshots[j].Date
+	})
+}
+
+// LatestSnapshot returns the most recent snapshot, or nil if there is none
+func (d *RepoData) LatestSnapshot() *Snapshot {
+	if len(d.Snapshots) == 0 {
+		return nil
+	}
+	return &d.Snapshots[len(d.Snapshots)-1]
+}
+
+// MergeStargazers adds newly listed stargazers, skipping the ones already stored
+func (d *RepoData) MergeStargazers(stargazers []Stargazer) int {
+	known := make(map[string]bool, len(d.Stargazers))
+	for _, stargazer := range d.Stargazers {
+		known[stargazer.key()] = true
+	}
+
+	added := 0
+	for _, stargazer := range stargazers {
+		if known[stargazer.key()] {
+			continue
+		}
+		known[stargazer.key()] = true
+		d.Stargazers = append(d.Stargazers, stargazer)
+		added++
+	}
+
+	sort.SliceStable(d.Stargazers, func(i, j int) bool {
+		return d.Stargazers[i].StarredAt.Before(d.Stargazers[j].StarredAt)
+	})
+	return added
+}
+
+// ReconcileStargazers keeps only the stored stargazers found in the complete list
+// of current stargazers, dropping the users who removed their stars, and adds the
+// missing ones. It returns the number of removed stargazers.
+func (d *RepoData) ReconcileStargazers(current []Stargazer) int {
+	live := make(map[string]bool, len(current))
+	for _, stargazer := range current {
+		live[stargazer.key()] = true
+	}
+
+	kept := d.Stargazers[:0]
+	for _, stargazer := range d.Stargazers {
+		if live[stargazer.key()] {
+			kept = append(kept, stargazer)
+		}
+	}
+	removed := len(d.Stargazers) - len(kept)
+	d.Stargazers = kept
+	d.MergeStargazers(current)
+	return removed
+}
@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStargazer(login string, starredAt time.Time) Stargazer {
+	stargazer := Stargazer{StarredAt: starredAt}
+	stargazer.User.Login = login
+	return stargazer
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	// Missing data is empty
+	data, err := store.Load("elliotxx/osp")
+	assert.NoError(t, err)
+	assert.Empty(t, data.Snapshots)
+
+	// Saved data is loaded back
+	day := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	data.AddSnapshot(day, Stats{Stars: 1})
+	data.MergeStargazers([]Stargazer{newStargazer("user1", day)})
+	assert.NoError(t, store.Save("elliotxx/osp", data))
+
+	loaded, err := store.Load("elliotxx/osp")
+	assert.NoError(t, err)
+	assert.Equal(t, data, loaded)
+}
+
+func TestAddSnapshot(t *testing.T) {
+	data := &RepoData{}
+	data.AddSnapshot(time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), Stats{Stars: 2})
+	data.AddSnapshot(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Stats{Stars: 1})
+	data.AddSnapshot(time.Date(2025, 1, 2, 20, 0, 0, 0, time.UTC), Stats{Stars: 3})
+
+	assert.Equal(t, []Snapshot{
+		{Date: "2025-01-01", Stats: Stats{Stars: 1}},
+		{Date: "2025-01-02", Stats: Stats{Stars: 3}},
+	}, data.Snapshots)
+	assert.Equal(t, 3, data.LatestSnapshot().Stars)
+}
+
+func TestMergeStargazers(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	data := &RepoData{}
+
+	added := data.MergeStargazers([]Stargazer{newStargazer("user1", day(1)), newStargazer("user2", day(2))})
+	assert.Equal(t, 2, added)
+
+	// Overlapping pages only add unknown stargazers
+	added = data.MergeStargazers([]Stargazer{newStargazer("user2", day(2)), newStargazer("user3", day(3))})
+	assert.Equal(t, 1, added)
+
+	logins := make([]string, 0, len(data.Stargazers))
+	for _, stargazer := range data.Stargazers {
+		logins = append(logins, stargazer.User.Login)
+	}
+	assert.Equal(t, []string{"user1", "user2", "user3"}, logins)
+}
+
+func TestReconcileStargazers(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	data := &RepoData{}
+	data.MergeStargazers([]Stargazer{newStargazer("user1", day(1)), newStargazer("user2", day(2)), newStargazer("user3", day(3))})
+
+	// user2 removed their star and user4 starred
+	removed := data.ReconcileStargazers([]Stargazer{newStargazer("user1", day(1)), newStargazer("user3", day(3)), newStargazer("user4", day(4))})
+	assert.Equal(t, 1, removed)
+
+	logins := make([]string, 0, len(data.Stargazers))
+	for _, stargazer := range data.Stargazers {
+		logins = append(logins, stargazer.User.Login)
+	}
+	assert.Equal(t, []string{"user1", "user3", "user4"}, logins)
+}
@@ -0,0 +1,163 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// SVGWidth is the default width of the SVG chart
+	SVGWidth = 800
+	// SVGHeight is the default height of the SVG chart
+	SVGHeight = 500
+
+	// svgMargin is the space around the plot area for the title, legend and axis labels
+	svgMarginTop    = 60
+	svgMarginRight  = 30
+	svgMarginBottom = 50
+	svgMarginLeft   = 70
+
+	// svgTicks is the approximate number of ticks on each axis
+	svgTicks = 5
+)
+
+// svgColors are the colors of the lines, assigned to repositories in order
+var svgColors = []string{"#2f81f7", "#e5534b", "#57ab5a", "#c69026", "#986ee2", "#39c5cf", "#cc6b2c", "#8ddb8c"}
+
+// StarSeries represents the star history of a repository
+type StarSeries struct {
+	Repository string        `json:"repository"`
+	History    []StarHistory `json:"history"`
+}
+
+// chartLayout holds the axes and plot area of a star history chart, shared by
+// the SVG and PNG renderers
+type chartLayout struct {
+	width, height         int
+	plotWidth, plotHeight float64
+	from, to              time.Time
+	step, top             int    // Star axis step between ticks and maximum
+	dateLayout            string // Layout of the dates on the time axis
+}
+
+// newChartLayout computes the axes of the chart fitting all series
+func newChartLayout(series []StarSeries, width, height int) chartLayout {
+	if width <= 0 {
+		width = SVGWidth
+	}
+	if height <= 0 {
+		height = SVGHeight
+	}
+	l := chartLayout{
+		width:      width,
+		height:     height,
+		plotWidth:  float64(width - svgMarginLeft - svgMarginRight),
+		plotHeight: float64(height - svgMarginTop - svgMarginBottom),
+		dateLayout: "2006-01-02",
+	}
+
+	// Find the range of the axes
+	maxStars := 0
+	for _, s := range series {
+		for _, h := range s.History {
+			if l.from.IsZero() || h.Date.Before(l.from) {
+				l.from = h.Date
+			}
+			if h.Date.After(l.to) {
+				l.to = h.Date
+			}
+			maxStars = max(maxStars, h.Stars)
+		}
+	}
+	if !l.to.After(l.from) {
+		l.to = l.from.AddDate(0, 0, 1)
+	}
+	l.step = niceStep(max(maxStars, 1))
+	l.top = int(math.Ceil(float64(max(maxStars, 1))/float64(l.step))) * l.step
+	if l.to.Sub(l.from) > 2*365*24*time.Hour {
+		l.dateLayout = "2006-01"
+	}
+	return l
+}
+
+// x returns the horizontal position of a date
+func (l chartLayout) x(t time.Time) float64 {
+	return svgMarginLeft + l.plotWidth*float64(t.Sub(l.from))/float64(l.to.Sub(l.from))
+}
+
+// y returns the vertical position of a star count
+func (l chartLayout) y(stars int) float64 {
+	return svgMarginTop + l.plotHeight*(1-float64(stars)/float64(l.top))
+}
+
+// dates returns the evenly spaced dates labeled on the time axis
+func (l chartLayout) dates() []time.Time {
+	dates := make([]time.Time, 0, svgTicks+1)
+	for i := 0; i <= svgTicks; i++ {
+		dates = append(dates, l.from.Add(time.Duration(i)*l.to.Sub(l.from)/svgTicks))
+	}
+	return dates
+}
+
+// RenderStarHistorySVG renders the star history of one or more repositories as
+// a self-contained SVG line chart sharing the same time and star axes
+func RenderStarHistorySVG(series []StarSeries, width, height int) string {
+	l := newChartLayout(series, width, height)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif" font-size="12">`+"\n",
+		l.width, l.height, l.width, l.height)
+	fmt.Fprintf(&b, `  <rect width="%d" height="%d" fill="#ffffff"/>`+"\n", l.width, l.height)
+	fmt.Fprintf(&b, `  <text x="%d" y="28" font-size="18" font-weight="bold" fill="#24292f">Star History</text>`+"\n", svgMarginLeft)
+
+	// Draw the star axis with grid lines
+	for stars := 0; stars <= l.top; stars += l.step {
+		fmt.Fprintf(&b, `  <line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#d0d7de" stroke-width="1"/>`+"\n",
+			svgMarginLeft, l.y(stars), svgMarginLeft+l.plotWidth, l.y(stars))
+		fmt.Fprintf(&b, `  <text x="%d" y="%.1f" text-anchor="end" fill="#57606a">%s</text>`+"\n",
+			svgMarginLeft-8, l.y(stars)+4, formatCount(stars))
+	}
+
+	// Draw the date axis with evenly spaced dates
+	for _, date := range l.dates() {
+		fmt.Fprintf(&b, `  <text x="%.1f" y="%.1f" text-anchor="middle" fill="#57606a">%s</text>`+"\n",
+			l.x(date), svgMarginTop+l.plotHeight+20, date.Format(l.dateLayout))
+	}
+	fmt.Fprintf(&b, `  <line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#57606a" stroke-width="1"/>`+"\n",
+		svgMarginLeft, svgMarginTop+l.plotHeight, svgMarginLeft+l.plotWidth, svgMarginTop+l.plotHeight)
+
+	// Draw a line and a legend entry for each repository
+	for i, s := range series {
+		color := svgColors[i%len(svgColors)]
+		if len(s.History) > 0 {
+			points := make([]string, 0, len(s.History))
+			for _, h := range s.History {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", l.x(h.Date), l.y(h.Stars)))
+			}
+			fmt.Fprintf(&b, `  <polyline points="%s" fill="none" stroke="%s" stroke-width="2" stroke-linejoin="round"/>`+"\n",
+				strings.Join(points, " "), color)
+		}
+
+		legendY := svgMarginTop + 10 + i*18
+		fmt.Fprintf(&b, `  <rect x="%d" y="%d" width="12" height="12" rx="2" fill="%s"/>`+"\n", svgMarginLeft+10, legendY-10, color)
+		fmt.Fprintf(&b, `  <text x="%d" y="%d" fill="#24292f">%s</text>`+"\n", svgMarginLeft+28, legendY, html.EscapeString(s.Repository))
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// niceStep returns a round step between axis ticks for values up to n
+func niceStep(n int) int {
+	raw := float64(n) / svgTicks
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if step := factor * magnitude; step >= raw {
+			return max(int(step), 1)
+		}
+	}
+	return max(int(10*magnitude), 1)
+}
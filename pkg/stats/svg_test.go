@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderStarHistorySVG(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	series := []StarSeries{
+		{Repository: "owner/a", History: []StarHistory{{Date: day(1), Stars: 10}, {Date: day(2), Stars: 40}}},
+		{Repository: "owner/<b>", History: []StarHistory{{Date: day(2), Stars: 5}, {Date: day(3), Stars: 120}}},
+	}
+
+	svg := RenderStarHistorySVG(series, 0, 0)
+	assert.NoError(t, xml.Unmarshal([]byte(svg), new(interface{})))
+	assert.Equal(t, 2, strings.Count(svg, "<polyline"))
+	assert.Contains(t, svg, "owner/a")
+	assert.Contains(t, svg, "owner/&lt;b&gt;")
+	// The star axis is rounded up to a nice step
+	assert.Contains(t, svg, ">150</text>")
+}
+
+func TestNiceStep(t *testing.T) {
+	assert.Equal(t, 1, niceStep(3))
+	assert.Equal(t, 20, niceStep(80))
+	assert.Equal(t, 50, niceStep(120))
+	assert.Equal(t, 1000, niceStep(4321))
+}
+
+func TestRenderStarHistoryPNG(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	series := []StarSeries{
+		{Repository: "owner/a", History: []StarHistory{{Date: day(1), Stars: 10}, {Date: day(2), Stars: 40}}},
+	}
+
+	data, err := RenderStarHistoryPNG(series, 0, 0)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, SVGWidth*pngScale, SVGHeight*pngScale), img.Bounds())
+
+	// The line is drawn in the color of the first repository from the first to the last point
+	l := newChartLayout(series, 0, 0)
+	for _, h := range series[0].History {
+		r, g, b, _ := img.At(int(l.x(h.Date)*pngScale), int(l.y(h.Stars)*pngScale)).RGBA()
+		assert.Equal(t, parseHexColor(svgColors[0]), color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 0xff})
+	}
+}
@@ -0,0 +1,111 @@
+package custom
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// blockPattern matches a custom block such as:
+//
+//	<!-- CUSTOM:START announcement -->
+//	Hand-written content
+//	<!-- CUSTOM:END -->
+//
+// The name is optional, blocks without a name are matched with each other.
+var blockPattern = regexp.MustCompile(`(?s)<!--\s*CUSTOM:START(?:\s+([\w./-]+))?\s*-->(.*?)<!--\s*CUSTOM:END\s*-->`)
+
+// Block represents a custom block in an issue body
+type Block struct {
+	Name    string
+	Content string
+}
+
+// String renders the block with its markers
+func (b Block) String() string {
+	if b.Name == "" {
+		return fmt.Sprintf("<!-- CUSTOM:START -->%s<!-- CUSTOM:END -->", b.Content)
+	}
+	return fmt.Sprintf("<!-- CUSTOM:START %s -->%s<!-- CUSTOM:END -->", b.Name, b.Content)
+}
+
+// Parse returns all custom blocks in body in order of appearance
+func Parse(body string) []Block {
+	matches := blockPattern.FindAllStringSubmatch(body, -1)
+	blocks := make([]Block, 0, len(matches))
+	for _, match := range matches {
+		blocks = append(blocks, Block{Name: match[1], Content: match[2]})
+	}
+	return blocks
+}
+
+// Merge re-inserts the custom blocks of the existing body into the generated
+// body. Blocks are matched by name, so they survive even if the anchors are
+// reordered in the template:
+//   - Anchors without a matching block keep the content rendered by the template.
+//   - Blocks sharing a name fill the anchors with that name in order of appearance,
+//     extra blocks being concatenated into the last anchor.
+//   - Blocks without a matching anchor are appended to the end, so that hand-written
+//     content is never lost silently.
+func Merge(existing, generated string) string {
+	blocks := Parse(existing)
+	if len(blocks) == 0 {
+		return generated
+	}
+
+	// Collect blocks by name in order of appearance
+	var names []string
+	contents := make(map[string][]string)
+	for _, block := range blocks {
+		if _, ok := contents[block.Name]; !ok {
+			names = append(names, block.Name)
+		}
+		contents[block.Name] = append(contents[block.Name], block.Content)
+	}
+
+	// Count the anchors of each name in the generated body
+	anchors := make(map[string]int)
+	for _, anchor := range Parse(generated) {
+		anchors[anchor.Name]++
+	}
+	for name, count := range anchors {
+		if len(contents[name]) > count {
+			log.Warn("Found %d custom blocks '%s' for %d anchors, merging the extra ones", len(contents[name]), name, count)
+		}
+	}
+
+	// Fill the anchors in the generated body
+	used := make(map[string]bool)
+	filled := make(map[string]int)
+	merged := blockPattern.ReplaceAllStringFunc(generated, func(anchor string) string {
+		match := blockPattern.FindStringSubmatch(anchor)
+		name := match[1]
+		i := filled[name]
+		filled[name]++
+		if i >= len(contents[name]) {
+			return anchor
+		}
+		used[name] = true
+		content := contents[name][i]
+		if i == anchors[name]-1 && i < len(contents[name])-1 {
+			content = strings.Join(contents[name][i:], "\n")
+		}
+		return Block{Name: name, Content: content}.String()
+	})
+
+	// Keep blocks whose anchors no longer exist
+	var orphans []string
+	for _, name := range names {
+		if !used[name] {
+			log.Warn("No anchor found for custom block '%s', appending it to the end", name)
+			orphans = append(orphans, Block{Name: name, Content: strings.Join(contents[name], "\n")}.String())
+		}
+	}
+	if len(orphans) > 0 {
+		merged = strings.TrimRight(merged, "\n") + "\n\n" + strings.Join(orphans, "\n\n") + "\n"
+	}
+
+	return merged
+}
@@ -0,0 +1,85 @@
+package custom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	body := "intro\n<!-- CUSTOM:START notice -->\nHello\n<!-- CUSTOM:END -->\nmiddle\n<!--CUSTOM:START-->anonymous<!--CUSTOM:END-->"
+
+	blocks := Parse(body)
+	assert.Equal(t, []Block{
+		{Name: "notice", Content: "\nHello\n"},
+		{Name: "", Content: "anonymous"},
+	}, blocks)
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		generated string
+		want      string
+	}{
+		{
+			name:      "no existing blocks",
+			existing:  "old content",
+			generated: "## Overview\n<!-- CUSTOM:START notice -->default<!-- CUSTOM:END -->\n",
+			want:      "## Overview\n<!-- CUSTOM:START notice -->default<!-- CUSTOM:END -->\n",
+		},
+		{
+			name:      "block is preserved",
+			existing:  "## Old\n<!-- CUSTOM:START notice -->\nRelease freeze!\n<!-- CUSTOM:END -->\n",
+			generated: "## New\n<!-- CUSTOM:START notice -->\n<!-- CUSTOM:END -->\n",
+			want:      "## New\n<!-- CUSTOM:START notice -->\nRelease freeze!\n<!-- CUSTOM:END -->\n",
+		},
+		{
+			name:      "missing block keeps generated default",
+			existing:  "<!-- CUSTOM:START notice -->kept<!-- CUSTOM:END -->",
+			generated: "<!-- CUSTOM:START notice --><!-- CUSTOM:END -->\n<!-- CUSTOM:START footer -->default<!-- CUSTOM:END -->\n",
+			want:      "<!-- CUSTOM:START notice -->kept<!-- CUSTOM:END -->\n<!-- CUSTOM:START footer -->default<!-- CUSTOM:END -->\n",
+		},
+		{
+			name:      "duplicated blocks are concatenated",
+			existing:  "<!-- CUSTOM:START notice -->first<!-- CUSTOM:END -->\n<!-- CUSTOM:START notice -->second<!-- CUSTOM:END -->",
+			generated: "<!-- CUSTOM:START notice --><!-- CUSTOM:END -->\n",
+			want:      "<!-- CUSTOM:START notice -->first\nsecond<!-- CUSTOM:END -->\n",
+		},
+		{
+			name:      "duplicated anchors are filled in order",
+			existing:  "<!-- CUSTOM:START notice -->first<!-- CUSTOM:END -->\n<!-- CUSTOM:START notice -->second<!-- CUSTOM:END -->",
+			generated: "<!-- CUSTOM:START notice --><!-- CUSTOM:END -->\nbody\n<!-- CUSTOM:START notice --><!-- CUSTOM:END -->\n",
+			want:      "<!-- CUSTOM:START notice -->first<!-- CUSTOM:END -->\nbody\n<!-- CUSTOM:START notice -->second<!-- CUSTOM:END -->\n",
+		},
+		{
+			name:      "duplicated anchors without enough blocks keep generated default",
+			existing:  "<!-- CUSTOM:START notice -->first<!-- CUSTOM:END -->",
+			generated: "<!-- CUSTOM:START notice --><!-- CUSTOM:END -->\nbody\n<!-- CUSTOM:START notice -->default<!-- CUSTOM:END -->\n",
+			want:      "<!-- CUSTOM:START notice -->first<!-- CUSTOM:END -->\nbody\n<!-- CUSTOM:START notice -->default<!-- CUSTOM:END -->\n",
+		},
+		{
+			name:      "reordered anchors are matched by name",
+			existing:  "<!-- CUSTOM:START a -->A<!-- CUSTOM:END -->\n<!-- CUSTOM:START b -->B<!-- CUSTOM:END -->",
+			generated: "<!-- CUSTOM:START b --><!-- CUSTOM:END -->\nbody\n<!-- CUSTOM:START a --><!-- CUSTOM:END -->\n",
+			want:      "<!-- CUSTOM:START b -->B<!-- CUSTOM:END -->\nbody\n<!-- CUSTOM:START a -->A<!-- CUSTOM:END -->\n",
+		},
+		{
+			name:      "blocks without anchor are appended",
+			existing:  "<!-- CUSTOM:START removed -->keep me<!-- CUSTOM:END -->",
+			generated: "## Overview\n\n",
+			want:      "## Overview\n\n<!-- CUSTOM:START removed -->keep me<!-- CUSTOM:END -->\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(tt.existing, tt.generated)
+			assert.Equal(t, tt.want, got)
+
+			// Merging again must not change the content, or the body would grow on each run
+			assert.Equal(t, got, Merge(got, tt.generated))
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// ErrChanges is returned in diff mode when the generated content differs from the existing one
+var ErrChanges = errors.New("changes detected")
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+// maxMatrixSize limits the memory used by the line matching, larger inputs
+// are reported as a full replacement
+const maxMatrixSize = 4 << 20
+
+// opKind represents the kind of an edit operation
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op represents a single line edit operation
+type op struct {
+	kind opKind
+	line string
+}
+
+// Normalize converts line endings to LF and trims trailing whitespace, since
+// GitHub stores issue bodies edited in the browser with CRLF line endings
+func Normalize(text string) string {
+	return strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), " \t\n")
+}
+
+// Equal reports whether two texts are equal after normalization
+func Equal(oldText, newText string) bool {
+	return Normalize(oldText) == Normalize(newText)
+}
+
+// Unified returns the unified diff of two texts with n lines of context, or
+// nil if they are equal after normalization
+func Unified(oldText, newText string, n int) []string {
+	if Equal(oldText, newText) {
+		return nil
+	}
+	oldLines := splitLines(Normalize(oldText))
+	newLines := splitLines(Normalize(newText))
+	ops := compare(oldLines, newLines)
+
+	var out []string
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == opEqual {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk until there are more than 2n unchanged lines in a row
+		end := start
+		for i, equal := start, 0; i < len(ops); i++ {
+			if ops[i].kind == opEqual {
+				equal++
+				if equal > 2*n {
+					break
+				}
+				continue
+			}
+			equal = 0
+			end = i + 1
+		}
+		from := max(start-n, 0)
+		to := min(end+n, len(ops))
+
+		// Compute hunk header positions
+		oldStart, newStart := 1, 1
+		for _, o := range ops[:from] {
+			if o.kind != opInsert {
+				oldStart++
+			}
+			if o.kind != opDelete {
+				newStart++
+			}
+		}
+		var oldCount, newCount int
+		var lines []string
+		for _, o := range ops[from:to] {
+			switch o.kind {
+			case opEqual:
+				oldCount++
+				newCount++
+				lines = append(lines, " "+o.line)
+			case opDelete:
+				oldCount++
+				lines = append(lines, "-"+o.line)
+			case opInsert:
+				newCount++
+				lines = append(lines, "+"+o.line)
+			}
+		}
+		// An empty range starts at the line before it
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount))
+		out = append(out, lines...)
+
+		start = to
+	}
+
+	return out
+}
+
+// Print prints the colorized unified diff of two texts and reports whether
+// there are any changes
+func Print(oldText, newText string) bool {
+	lines := Unified(oldText, newText, DefaultContext)
+	for _, line := range lines {
+		switch line[0] {
+		case '@':
+			log.C(log.ColorCyan).Log("%s", line)
+		case '-':
+			log.C(log.ColorRed).Log("%s", line)
+		case '+':
+			log.C(log.ColorGreen).Log("%s", line)
+		default:
+			log.C(log.ColorGray).Log("%s", line)
+		}
+	}
+	return len(lines) > 0
+}
+
+// splitLines splits text into lines, an empty text has no lines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// compare returns the edit script turning a into b based on their longest common subsequence
+func compare(a, b []string) []op {
+	// Skip common prefix and suffix, which keeps the matrix small for typical updates
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{opEqual, line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	if (len(midA)+1)*(len(midB)+1) > maxMatrixSize {
+		for _, line := range midA {
+			ops = append(ops, op{opDelete, line})
+		}
+		for _, line := range midB {
+			ops = append(ops, op{opInsert, line})
+		}
+	} else {
+		ops = append(ops, lcs(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{opEqual, line})
+	}
+	return ops
+}
+
+// lcs computes the edit script of a and b using dynamic programming
+func lcs(a, b []string) []op {
+	// lengths[i][j] is the length of the LCS of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
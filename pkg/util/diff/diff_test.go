@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal("a\r\nb\r\n", "a\nb"))
+	assert.True(t, Equal("", "\n"))
+	assert.False(t, Equal("a\nb", "a\nc"))
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		context int
+		want    []string
+	}{
+		{
+			name:    "no changes",
+			oldText: "a\nb\n",
+			newText: "a\r\nb",
+			context: 3,
+			want:    nil,
+		},
+		{
+			name:    "new content",
+			oldText: "",
+			newText: "a\nb",
+			context: 3,
+			want:    []string{"@@ -0,0 +1,2 @@", "+a", "+b"},
+		},
+		{
+			name:    "single change with context",
+			oldText: "1\n2\n3\n4\n5\n6\n7",
+			newText: "1\n2\n3\nfour\n5\n6\n7",
+			context: 1,
+			want:    []string{"@@ -3,3 +3,3 @@", " 3", "-4", "+four", " 5"},
+		},
+		{
+			name:    "separate hunks",
+			oldText: "1\n2\n3\n4\n5\n6\n7",
+			newText: "one\n2\n3\n4\n5\n6\nseven",
+			context: 1,
+			want: []string{
+				"@@ -1,2 +1,2 @@", "-1", "+one", " 2",
+				"@@ -6,2 +6,2 @@", " 6", "-7", "+seven",
+			},
+		},
+		{
+			name:    "close changes share a hunk",
+			oldText: "1\n2\n3\n4",
+			newText: "one\n2\n3\nfour",
+			context: 1,
+			want:    []string{"@@ -1,4 +1,4 @@", "-1", "+one", " 2", " 3", "-4", "+four"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Unified(tt.oldText, tt.newText, tt.context))
+		})
+	}
+}
@@ -36,3 +36,38 @@ func GetCurrentHead() (string, error) {
 func GetTagCommit(tag string) (string, error) {
 	return runGitCommand("rev-list", "-n", "1", tag)
 }
+
+// GetPreviousTag returns the latest tag reachable from the parent of a given ref
+func GetPreviousTag(ref string) (string, error) {
+	return runGitCommand("describe", "--tags", "--abbrev=0", ref+"^")
+}
+
+// Commit represents a commit in the Git history
+type Commit struct {
+	SHA     string
+	Message string
+}
+
+// GetCommitsSince returns the commits reachable from HEAD but not from a given
+// ref, newest first. If ref is empty, the whole history is returned.
+func GetCommitsSince(ref string) ([]Commit, error) {
+	rev := "HEAD"
+	if ref != "" {
+		rev = ref + "..HEAD"
+	}
+	// Separate the fields with NUL and the commits with the record separator
+	out, err := runGitCommand("log", "--format=%H%x00%B%x1e", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		sha, message, ok := strings.Cut(strings.TrimSpace(record), "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, Commit{SHA: sha, Message: strings.TrimSpace(message)})
+	}
+	return commits, nil
+}
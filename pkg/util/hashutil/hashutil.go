@@ -0,0 +1,39 @@
+package hashutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// markerPattern matches the hidden hash marker in an issue body
+var markerPattern = regexp.MustCompile(`<!--\s*osp:hash=([0-9a-f]+)\s*-->`)
+
+// Sum returns the hex encoded SHA-256 hash of the content
+func Sum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Mark appends a hidden hash marker to the content, replacing any existing one
+func Mark(content, hash string) string {
+	content = strings.TrimRight(markerPattern.ReplaceAllString(content, ""), "\n")
+	return fmt.Sprintf("%s\n<!-- osp:hash=%s -->\n", content, hash)
+}
+
+// Extract returns the hash carried by the marker in body, or an empty string if there is none
+func Extract(body string) string {
+	match := markerPattern.FindStringSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// Match reports whether both bodies carry the same hash marker
+func Match(oldBody, newBody string) bool {
+	oldHash := Extract(oldBody)
+	return oldHash != "" && oldHash == Extract(newBody)
+}
@@ -0,0 +1,54 @@
+package hashutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMark(t *testing.T) {
+	hash := Sum("content")
+	assert.Len(t, hash, 64)
+
+	marked := Mark("## Overview\n\n", hash)
+	assert.Equal(t, "## Overview\n<!-- osp:hash="+hash+" -->\n", marked)
+	assert.Equal(t, hash, Extract(marked))
+
+	// Marking again replaces the existing marker
+	remarked := Mark(marked, "abc")
+	assert.Equal(t, "## Overview\n<!-- osp:hash=abc -->\n", remarked)
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldBody string
+		newBody string
+		want    bool
+	}{
+		{
+			name:    "same hash",
+			oldBody: "old\r\n<!-- osp:hash=abc -->",
+			newBody: "new\n<!-- osp:hash=abc -->\n",
+			want:    true,
+		},
+		{
+			name:    "different hash",
+			oldBody: "<!-- osp:hash=abc -->",
+			newBody: "<!-- osp:hash=def -->",
+			want:    false,
+		},
+		{
+			name:    "no marker in existing body",
+			oldBody: "old",
+			newBody: "<!-- osp:hash=abc -->",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.oldBody, tt.newBody))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package issueutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elliotxx/osp/pkg/log"
+	"github.com/elliotxx/osp/pkg/util/custom"
+	"github.com/elliotxx/osp/pkg/util/diff"
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/elliotxx/osp/pkg/util/prompt"
+)
+
+// Issue represents an existing or saved issue or discussion
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	URL    string
+}
+
+// Target describes the issue or discussion to create or update with generated content
+type Target struct {
+	Kind     string // Kind of the target used in messages, e.g. "planning issue"
+	Title    string // Title of the target
+	Existing *Issue // Existing target, or nil to create a new one
+	Force    bool   // If true, save the target even if its content is up to date
+
+	// Save creates the target, or updates the existing one, with the content
+	Save func(content string) (*Issue, error)
+}
+
+// Options represents the options of an update
+type Options struct {
+	DryRun      bool // If true, only show the changes without applying them
+	AutoConfirm bool // If true, skip confirmation prompt
+	Diff        bool // If true, only show the changes and return diff.ErrChanges if there are any
+}
+
+// Update creates or updates the target with the generated content. The update
+// is skipped if the hash marker or the content of the existing target is
+// unchanged, and custom blocks of the existing target are preserved. It
+// returns the saved or up to date target, or nil if nothing was saved because
+// of the dry-run mode or a cancellation.
+func Update(target Target, content string, opts Options) (*Issue, error) {
+	existing := target.Existing
+	titleChanged := existing != nil && existing.Title != target.Title
+	if existing != nil {
+		// Skip the update if the generated content has not changed since the last run
+		if !titleChanged && !target.Force && hashutil.Match(existing.Body, content) {
+			log.Success("%s #%d is up to date, skipping update", capitalize(target.Kind), existing.Number)
+			return existing, nil
+		}
+
+		// Preserve custom blocks written by hand in the existing target
+		content = custom.Merge(existing.Body, content)
+	}
+
+	// Show preview
+	if existing == nil {
+		log.Info("Creating new %s '%s'", target.Kind, target.Title)
+
+		// Preview the content
+		log.C(log.ColorBlue).P("↓").Log("Preview of the %s content:", target.Kind)
+		log.C(log.ColorCyan).Log("%s", content)
+	} else {
+		log.Info("Updating existing %s #%d (%s)", target.Kind, existing.Number, target.Title)
+
+		// Skip the update if nothing changed
+		if !titleChanged && !target.Force && diff.Equal(existing.Body, content) {
+			log.Success("%s #%d is up to date, skipping update", capitalize(target.Kind), existing.Number)
+			return existing, nil
+		}
+
+		// Preview the changes
+		log.C(log.ColorBlue).P("↓").Log("Changes to the %s content:", target.Kind)
+		if titleChanged {
+			log.C(log.ColorRed).Log("-Title: %s", existing.Title)
+			log.C(log.ColorGreen).Log("+Title: %s", target.Title)
+		}
+		diff.Print(existing.Body, content)
+	}
+
+	if opts.Diff {
+		return nil, fmt.Errorf("%s '%s' is out of date: %w", target.Kind, target.Title, diff.ErrChanges)
+	}
+
+	existingURL := ""
+	if existing != nil {
+		existingURL = existing.URL
+	}
+	confirmed, err := Confirm(target.Kind, existingURL, opts)
+	if err != nil || !confirmed {
+		return nil, err
+	}
+
+	// Create or update the target
+	saved, err := target.Save(content)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		log.Success("Successfully created %s #%d", target.Kind, saved.Number).
+			L(1).P("→").Log("%s URL: %s", capitalize(target.Kind), saved.URL)
+	} else {
+		log.Success("Successfully updated %s #%d", target.Kind, saved.Number).
+			L(1).P("→").Log("%s URL: %s", capitalize(target.Kind), saved.URL)
+	}
+
+	return saved, nil
+}
+
+// Confirm reports whether to proceed with saving the previewed changes of a
+// target of the kind, which is created if existingURL is empty. Nothing is saved
+// in the dry-run mode, and the confirmation prompt is skipped if auto-confirm
+// is enabled.
+func Confirm(kind, existingURL string, opts Options) (bool, error) {
+	if opts.DryRun {
+		log.Warn("Dry-run mode, skipping update")
+		return false, nil
+	}
+
+	// Ask for confirmation if auto-confirm is not enabled
+	if opts.AutoConfirm {
+		log.Warn("Auto-confirm is enabled, skipping confirmation")
+		return true, nil
+	}
+	if existingURL == "" {
+		log.Info("Will create a new %s with the above content", kind)
+	} else {
+		log.Info("Will update existing %s (%s) with the above changes", kind, existingURL)
+	}
+
+	confirmed, err := prompt.AskForConfirmation("Do you want to proceed with the update?")
+	if err != nil {
+		return false, err
+	}
+	if !confirmed {
+		log.Info("Update cancelled")
+	}
+	return confirmed, nil
+}
+
+// capitalize returns the text with its first letter in upper case
+func capitalize(text string) string {
+	if text == "" {
+		return text
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
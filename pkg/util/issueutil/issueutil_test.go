@@ -0,0 +1,81 @@
+package issueutil
+
+import (
+	"testing"
+
+	"github.com/elliotxx/osp/pkg/util/diff"
+	"github.com/elliotxx/osp/pkg/util/hashutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	content := hashutil.Mark("## Planning\n<!-- CUSTOM:START notice -->\n<!-- CUSTOM:END -->\n", "abc")
+	existing := &Issue{
+		Number: 7,
+		Title:  "Planning: v1.0",
+		Body:   "## Old\n<!-- CUSTOM:START notice -->\nFreeze!\n<!-- CUSTOM:END -->\n<!-- osp:hash=def -->\n",
+		URL:    "https://github.com/owner/repo/issues/7",
+	}
+
+	var saved []string
+	newTarget := func(existing *Issue, title string) Target {
+		return Target{
+			Kind:     "planning issue",
+			Title:    title,
+			Existing: existing,
+			Save: func(content string) (*Issue, error) {
+				saved = append(saved, content)
+				return &Issue{Number: 7, Title: title, Body: content}, nil
+			},
+		}
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		saved = nil
+		upToDate := &Issue{Number: 7, Title: "Planning: v1.0", Body: "edited\n<!-- osp:hash=abc -->"}
+		got, err := Update(newTarget(upToDate, "Planning: v1.0"), content, Options{AutoConfirm: true})
+		require.NoError(t, err)
+		assert.Equal(t, upToDate, got)
+		assert.Empty(t, saved)
+
+		// A new title is saved even if the content is unchanged
+		_, err = Update(newTarget(upToDate, "Planning: v1.0.0"), content, Options{AutoConfirm: true})
+		require.NoError(t, err)
+		assert.Len(t, saved, 1)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		saved = nil
+		_, err := Update(newTarget(existing, "Planning: v1.0"), content, Options{Diff: true})
+		assert.ErrorIs(t, err, diff.ErrChanges)
+		assert.Empty(t, saved)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		saved = nil
+		got, err := Update(newTarget(nil, "Planning: v1.0"), content, Options{DryRun: true})
+		require.NoError(t, err)
+		assert.Nil(t, got)
+		assert.Empty(t, saved)
+	})
+
+	t.Run("update preserves custom blocks", func(t *testing.T) {
+		saved = nil
+		got, err := Update(newTarget(existing, "Planning: v1.0"), content, Options{AutoConfirm: true})
+		require.NoError(t, err)
+		require.Len(t, saved, 1)
+		assert.Equal(t, "## Planning\n<!-- CUSTOM:START notice -->\nFreeze!\n<!-- CUSTOM:END -->\n<!-- osp:hash=abc -->\n", saved[0])
+		assert.Equal(t, 7, got.Number)
+	})
+}
+
+func TestConfirm(t *testing.T) {
+	confirmed, err := Confirm("draft release", "", Options{DryRun: true, AutoConfirm: true})
+	require.NoError(t, err)
+	assert.False(t, confirmed)
+
+	confirmed, err = Confirm("draft release", "https://github.com/owner/repo/releases/1", Options{AutoConfirm: true})
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+}
@@ -0,0 +1,238 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+	FormatTable = "table"
+)
+
+// FormatUsage is the usage of format flags supporting the shared output formats
+const FormatUsage = "Output format (text, json, yaml, csv, table) or a Go template such as '{{ .Stars }}'"
+
+// IsText reports whether the format is the human readable text format, which
+// every command renders by itself
+func IsText(format string) bool {
+	return format == "" || strings.EqualFold(format, FormatText)
+}
+
+// IsTemplate reports whether the format is a Go template
+func IsTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// Write writes v to w in the format. Field names of the json, yaml, csv and
+// table formats are the JSON field names of v, while templates are executed
+// with v itself.
+func Write(w io.Writer, format string, v interface{}) error {
+	if IsTemplate(format) {
+		return writeTemplate(w, format, v)
+	}
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal json: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case FormatYAML:
+		return writeYAML(w, v)
+	case FormatCSV:
+		return writeCSV(w, v)
+	case FormatTable:
+		return writeTable(w, v)
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+}
+
+// writeTemplate executes the Go template with v
+func writeTemplate(w io.Writer, format string, v interface{}) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return fmt.Errorf("failed to parse format template: %w", err)
+	}
+	if err := tmpl.Execute(w, v); err != nil {
+		return fmt.Errorf("failed to execute format template: %w", err)
+	}
+	return nil
+}
+
+// writeYAML writes v as YAML using its JSON field names
+func writeYAML(w io.Writer, v interface{}) error {
+	node, err := toNode(v)
+	if err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("failed to marshal yaml: %w", err)
+	}
+	return encoder.Close()
+}
+
+// writeCSV writes v as CSV with a header row
+func writeCSV(w io.Writer, v interface{}) error {
+	header, rows, err := toRows(v)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	return nil
+}
+
+// writeTable writes v as an aligned table with an upper case header row
+func writeTable(w io.Writer, v interface{}) error {
+	header, rows, err := toRows(v)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i := range header {
+		header[i] = strings.ToUpper(header[i])
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// toNode converts v into a YAML node through its JSON encoding, which keeps
+// the JSON field names and their order
+func toNode(v interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json: %w", err)
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert json: %w", err)
+	}
+	resetStyle(&node)
+	return &node, nil
+}
+
+// resetStyle clears the flow and quoting styles inherited from JSON, so that
+// the node is encoded in block style
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// toRows converts v into a header and rows. An object becomes a single row and
+// an array of objects one row each, with nested objects flattened into columns
+// named by their dotted path and nested arrays encoded as JSON.
+func toRows(v interface{}) ([]string, [][]string, error) {
+	node, err := toNode(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	var records []*yaml.Node
+	switch node.Kind {
+	case yaml.SequenceNode:
+		records = node.Content
+	default:
+		records = []*yaml.Node{node}
+	}
+
+	var header []string
+	columns := make(map[string]int)
+	var values []map[string]string
+	for _, record := range records {
+		fields := make(map[string]string)
+		if err := flatten(record, "", fields, func(key string) {
+			if _, ok := columns[key]; !ok {
+				columns[key] = len(header)
+				header = append(header, key)
+			}
+		}); err != nil {
+			return nil, nil, err
+		}
+		values = append(values, fields)
+	}
+
+	rows := make([][]string, 0, len(values))
+	for _, fields := range values {
+		row := make([]string, len(header))
+		for key, value := range fields {
+			row[columns[key]] = value
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, nil
+}
+
+// flatten collects the scalar fields of node into fields, calling addColumn
+// for each field in order
+func flatten(node *yaml.Node, prefix string, fields map[string]string, addColumn func(string)) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flatten(node.Content[i+1], key, fields, addColumn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.SequenceNode:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("failed to convert field '%s': %w", prefix, err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to convert field '%s': %w", prefix, err)
+		}
+		setField(prefix, string(data), fields, addColumn)
+		return nil
+	default:
+		value := node.Value
+		if node.Tag == "!!null" {
+			value = ""
+		}
+		setField(prefix, value, fields, addColumn)
+		return nil
+	}
+}
+
+// setField sets a field, naming a bare scalar "value"
+func setField(key, value string, fields map[string]string, addColumn func(string)) {
+	if key == "" {
+		key = "value"
+	}
+	addColumn(key)
+	fields[key] = value
+}
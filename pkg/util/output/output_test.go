@@ -0,0 +1,90 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	Date  time.Time `json:"date"`
+	Stars int       `json:"stars"`
+}
+
+type series struct {
+	Repository string `json:"repository"`
+	Owner      struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Points []point `json:"points"`
+}
+
+func TestWrite(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := []point{{Date: day, Stars: 1}, {Date: day.AddDate(0, 0, 1), Stars: 3}}
+	s := series{Repository: "owner/repo", Points: points[:1]}
+	s.Owner.Login = "owner"
+
+	tests := []struct {
+		name   string
+		format string
+		v      interface{}
+		want   string
+	}{
+		{
+			name:   "json",
+			format: "json",
+			v:      points[0],
+			want:   "{\n  \"date\": \"2025-01-01T00:00:00Z\",\n  \"stars\": 1\n}\n",
+		},
+		{
+			name:   "yaml",
+			format: "YAML",
+			v:      points,
+			want:   "- date: \"2025-01-01T00:00:00Z\"\n  stars: 1\n- date: \"2025-01-02T00:00:00Z\"\n  stars: 3\n",
+		},
+		{
+			name:   "csv",
+			format: "csv",
+			v:      points,
+			want:   "date,stars\n2025-01-01T00:00:00Z,1\n2025-01-02T00:00:00Z,3\n",
+		},
+		{
+			name:   "csv with nested fields",
+			format: "csv",
+			v:      s,
+			want:   "repository,owner.login,points\nowner/repo,owner,\"[{\"\"date\"\":\"\"2025-01-01T00:00:00Z\"\",\"\"stars\"\":1}]\"\n",
+		},
+		{
+			name:   "table",
+			format: "table",
+			v:      points,
+			want:   "DATE                  STARS\n2025-01-01T00:00:00Z  1\n2025-01-02T00:00:00Z  3\n",
+		},
+		{
+			name:   "template",
+			format: "{{ range . }}{{ .Stars }} {{ end }}",
+			v:      points,
+			want:   "1 3 ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, Write(&buf, tt.format, tt.v))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, Write(&buf, "xml", 1))
+	assert.Error(t, Write(&buf, "{{ .Missing }}", point{}))
+	assert.True(t, IsText(""))
+	assert.True(t, IsText("Text"))
+	assert.False(t, IsText("json"))
+}
@@ -0,0 +1,271 @@
+package tmplutil
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/elliotxx/osp/pkg/log"
+)
+
+// Load reads a user-supplied template. The source is first looked up on the
+// local disk; if no such file exists, it is treated as a path inside the
+// repository (e.g. ".github/osp/planning.gotmpl") and fetched from GitHub.
+func Load(client *api.RESTClient, repoName, source string) (string, error) {
+	data, err := os.ReadFile(source)
+	if err == nil {
+		log.Debug("Loaded template from local file %s", source)
+		return string(data), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read template %s: %w", source, err)
+	}
+
+	if client == nil || repoName == "" {
+		return "", fmt.Errorf("template %s not found", source)
+	}
+
+	var content struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	path := fmt.Sprintf("repos/%s/contents/%s", repoName, strings.TrimPrefix(source, "/"))
+	if err := client.Get(path, &content); err != nil {
+		return "", fmt.Errorf("template %s not found locally or in %s: %w", source, repoName, err)
+	}
+	if content.Encoding != "base64" {
+		return "", fmt.Errorf("unsupported encoding %q of template %s", content.Encoding, source)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode template %s: %w", source, err)
+	}
+	log.Debug("Loaded template %s from %s", source, repoName)
+
+	return string(decoded), nil
+}
+
+// Validate statically checks that every field referenced by the template
+// exists on the type of data. Unlike executing the template, it also covers
+// branches that would not be taken for a particular input, so that a typo in
+// a rarely rendered section is reported before anything is written to GitHub.
+//
+// Results of functions are not typed, so field accesses on them are skipped.
+func Validate(tmpl *template.Template, data interface{}) error {
+	root := reflect.TypeOf(data)
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		// Associated templates may be invoked with any data, so only the
+		// main template is known to receive data as dot
+		dot := root
+		if t.Name() != tmpl.Name() {
+			dot = nil
+		}
+		v := &validator{tree: t.Tree, vars: map[string]reflect.Type{"$": dot}}
+		if err := v.walk(t.Tree.Root, dot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validator walks a template parse tree keeping track of the type of dot
+type validator struct {
+	tree *parse.Tree
+	vars map[string]reflect.Type
+}
+
+// walk validates a node with the given type of dot, a nil type means unknown
+func (v *validator) walk(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := v.walk(child, dot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		_, err := v.pipe(n.Pipe, dot)
+		return err
+	case *parse.IfNode:
+		if _, err := v.pipe(n.Pipe, dot); err != nil {
+			return err
+		}
+		return v.branch(&n.BranchNode, dot, dot, nil)
+	case *parse.WithNode:
+		typ, err := v.pipe(n.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		return v.branch(&n.BranchNode, typ, dot, nil)
+	case *parse.RangeNode:
+		typ, err := v.pipe(n.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		key, elem := rangeTypes(typ)
+		return v.branch(&n.BranchNode, elem, dot, []reflect.Type{key, elem})
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			_, err := v.pipe(n.Pipe, dot)
+			return err
+		}
+	}
+	return nil
+}
+
+// branch validates the pipeline-independent parts of if, with and range nodes
+func (v *validator) branch(n *parse.BranchNode, inner, outer reflect.Type, rangeVars []reflect.Type) error {
+	// Range declarations bind the key and the element, or only the element
+	if rangeVars != nil && n.Pipe != nil {
+		switch len(n.Pipe.Decl) {
+		case 1:
+			v.vars[n.Pipe.Decl[0].Ident[0]] = rangeVars[1]
+		case 2:
+			v.vars[n.Pipe.Decl[0].Ident[0]] = rangeVars[0]
+			v.vars[n.Pipe.Decl[1].Ident[0]] = rangeVars[1]
+		}
+	}
+	if err := v.walk(n.List, inner); err != nil {
+		return err
+	}
+	return v.walk(n.ElseList, outer)
+}
+
+// pipe validates a pipeline and returns the type of its result
+func (v *validator) pipe(p *parse.PipeNode, dot reflect.Type) (reflect.Type, error) {
+	if p == nil {
+		return nil, nil
+	}
+	var typ reflect.Type
+	for i, cmd := range p.Cmds {
+		t, err := v.command(cmd, dot)
+		if err != nil {
+			return nil, err
+		}
+		// Only the first command of a pipeline can be typed reliably, the
+		// following ones are usually function calls receiving the result
+		if i == 0 {
+			typ = t
+		} else {
+			typ = nil
+		}
+	}
+	for _, decl := range p.Decl {
+		v.vars[decl.Ident[0]] = typ
+	}
+	return typ, nil
+}
+
+// command validates all arguments of a command and returns the type of its result
+func (v *validator) command(cmd *parse.CommandNode, dot reflect.Type) (reflect.Type, error) {
+	var typ reflect.Type
+	for i, arg := range cmd.Args {
+		t, err := v.arg(arg, dot)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 && len(cmd.Args) == 1 {
+			typ = t
+		}
+	}
+	return typ, nil
+}
+
+// arg validates a single argument and returns its type
+func (v *validator) arg(node parse.Node, dot reflect.Type) (reflect.Type, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return v.fields(n, dot, n.Ident)
+	case *parse.VariableNode:
+		typ, ok := v.vars[n.Ident[0]]
+		if !ok {
+			return nil, nil
+		}
+		return v.fields(n, typ, n.Ident[1:])
+	case *parse.ChainNode:
+		var typ reflect.Type
+		var err error
+		if pipe, ok := n.Node.(*parse.PipeNode); ok {
+			typ, err = v.pipe(pipe, dot)
+		} else {
+			typ, err = v.arg(n.Node, dot)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return v.fields(n, typ, n.Field)
+	case *parse.PipeNode:
+		return v.pipe(n, dot)
+	}
+	return nil, nil
+}
+
+// fields resolves a chain of field names starting from typ
+func (v *validator) fields(node parse.Node, typ reflect.Type, names []string) (reflect.Type, error) {
+	for _, name := range names {
+		if typ == nil {
+			return nil, nil
+		}
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if m, ok := reflect.PointerTo(typ).MethodByName(name); ok {
+			if m.Type.NumOut() == 0 {
+				return nil, nil
+			}
+			typ = m.Type.Out(0)
+			continue
+		}
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			f, ok := typ.FieldByName(name)
+			if !ok || !f.IsExported() {
+				location, _ := v.tree.ErrorContext(node)
+				return nil, fmt.Errorf("template: %s: can't evaluate field %s in type %s", location, name, typ)
+			}
+			typ = f.Type
+		case reflect.Map:
+			typ = typ.Elem()
+		default:
+			// Interfaces and other kinds can only be checked at execution time
+			return nil, nil
+		}
+	}
+	return typ, nil
+}
+
+// rangeTypes returns the key and element types when ranging over typ
+func rangeTypes(typ reflect.Type) (reflect.Type, reflect.Type) {
+	if typ == nil {
+		return nil, nil
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeOf(0), typ.Elem()
+	case reflect.Map:
+		return typ.Key(), typ.Elem()
+	case reflect.Chan:
+		return nil, typ.Elem()
+	}
+	return nil, nil
+}
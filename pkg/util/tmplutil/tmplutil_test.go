@@ -0,0 +1,108 @@
+package tmplutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	Name  string
+	Owner *struct{ Login string }
+}
+
+type testData struct {
+	Title string
+	DueOn *time.Time
+	Items []testItem
+	Index map[string][]testItem
+}
+
+func TestValidate(t *testing.T) {
+	funcMap := template.FuncMap{
+		"upper": func(s string) string { return s },
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{
+			name: "valid fields",
+			text: "{{ .Title }} {{ .DueOn.Format \"2006\" }}{{ range .Items }}{{ .Name }}{{ .Owner.Login }}{{ end }}",
+		},
+		{
+			name: "valid variables and maps",
+			text: "{{ range $key, $items := .Index }}{{ $key }}{{ range $item := $items }}{{ $item.Name }}{{ $.Title }}{{ end }}{{ end }}",
+		},
+		{
+			name: "valid variables declared in if and with",
+			text: "{{ if $items := .Items }}{{ range $items }}{{ .Name }}{{ end }}{{ end }}{{ with $due := .DueOn }}{{ $due.Year }}{{ end }}",
+		},
+		{
+			name: "function results are not checked",
+			text: "{{ with upper .Title }}{{ .Anything }}{{ end }}{{ $x := index .Items 0 }}{{ $x.Anything }}",
+		},
+		{
+			name:    "unknown top level field",
+			text:    "{{ .Titel }}",
+			wantErr: "can't evaluate field Titel in type tmplutil.testData",
+		},
+		{
+			name:    "unknown field in branch not taken",
+			text:    "{{ if false }}{{ range .Items }}{{ .Title }}{{ end }}{{ end }}",
+			wantErr: "can't evaluate field Title in type tmplutil.testItem",
+		},
+		{
+			name:    "unknown field in if condition",
+			text:    "{{ if .Titel }}{{ .Title }}{{ end }}",
+			wantErr: "can't evaluate field Titel in type tmplutil.testData",
+		},
+		{
+			name:    "unknown field on variable declared in if",
+			text:    "{{ if $items := .Items }}{{ range $items }}{{ .Title }}{{ end }}{{ end }}",
+			wantErr: "can't evaluate field Title in type tmplutil.testItem",
+		},
+		{
+			name:    "unknown field on variable",
+			text:    "{{ range $item := .Items }}{{ $item.Owner.Name }}{{ end }}",
+			wantErr: "can't evaluate field Name in type struct { Login string }",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(funcMap).Parse(tt.text)
+			assert.NoError(t, err)
+
+			err = Validate(tmpl, testData{})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "custom.gotmpl")
+	assert.NoError(t, os.WriteFile(path, []byte("{{ .Title }}"), 0o600))
+
+	t.Run("local file", func(t *testing.T) {
+		text, err := Load(nil, "", path)
+		assert.NoError(t, err)
+		assert.Equal(t, "{{ .Title }}", text)
+	})
+
+	t.Run("missing file without repository", func(t *testing.T) {
+		_, err := Load(nil, "", filepath.Join(dir, "missing.gotmpl"))
+		assert.ErrorContains(t, err, "not found")
+	})
+}